@@ -0,0 +1,101 @@
+// Package assets は埋め込みのスプライトシートを読み込み、描画に使う画像を管理します
+//
+// すべてのスプライトは1枚のシートの部分画像なので、続けて描画すると
+// ebiten がまとめて1回の描画命令にしてくれます
+package assets
+
+//go:generate go run ./gen
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+//go:embed sprites.png
+var spritesPNG []byte
+
+//go:embed sprites.json
+var spritesJSON []byte
+
+// sheet はスプライトシートの目録です
+type sheet struct {
+	FrameSize int `json:"frame_size"`
+	Sprites   []struct {
+		Name   string  `json:"name"`
+		Row    int     `json:"row"`
+		Frames int     `json:"frames"`
+		FPS    float64 `json:"fps"`
+	} `json:"sprites"`
+}
+
+// Sprite はコマ送りのアニメーションです
+type Sprite struct {
+	frames []*ebiten.Image
+	fps    float64
+}
+
+// Frame は開始から t 秒の時点のコマを返します
+func (s *Sprite) Frame(t float64) *ebiten.Image {
+	if len(s.frames) == 1 || s.fps <= 0 {
+		return s.frames[0]
+	}
+	i := int(t*s.fps) % len(s.frames)
+	return s.frames[max(i, 0)]
+}
+
+// Manager は読み込んだスプライトを名前で引けるようにします
+type Manager struct {
+	sprites map[string]*Sprite
+}
+
+// Load は埋め込みのスプライトシートを読み込みます
+// 起動時に1回だけ呼び、結果を使い回してください
+func Load() (*Manager, error) {
+	return load(spritesPNG, spritesJSON)
+}
+
+func load(pngData, jsonData []byte) (*Manager, error) {
+	var sh sheet
+	if err := json.Unmarshal(jsonData, &sh); err != nil {
+		return nil, fmt.Errorf("sprites.json: %w", err)
+	}
+	img, err := png.Decode(bytes.NewReader(pngData))
+	if err != nil {
+		return nil, fmt.Errorf("sprites.png: %w", err)
+	}
+	atlas := ebiten.NewImageFromImage(img)
+
+	m := &Manager{sprites: make(map[string]*Sprite, len(sh.Sprites))}
+
+	size := sh.FrameSize
+	for _, s := range sh.Sprites {
+		sp := &Sprite{fps: s.FPS}
+		for f := 0; f < s.Frames; f++ {
+			r := image.Rect(f*size, s.Row*size, (f+1)*size, (s.Row+1)*size)
+			if !r.In(atlas.Bounds()) {
+				return nil, fmt.Errorf("sprites.json: sprite %q frame %d is outside the sheet", s.Name, f)
+			}
+			sp.frames = append(sp.frames, atlas.SubImage(r).(*ebiten.Image))
+		}
+		if len(sp.frames) == 0 {
+			return nil, fmt.Errorf("sprites.json: sprite %q has no frames", s.Name)
+		}
+		m.sprites[s.Name] = sp
+	}
+	return m, nil
+}
+
+// Sprite は name のスプライトを返します。なければ nil を返します
+// m が nil（読み込みに失敗したとき）でも呼べます
+func (m *Manager) Sprite(name string) *Sprite {
+	if m == nil {
+		return nil
+	}
+	return m.sprites[name]
+}
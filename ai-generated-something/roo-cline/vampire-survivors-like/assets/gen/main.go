@@ -0,0 +1,270 @@
+// gen はスプライトシート (sprites.png) とその目録 (sprites.json) を生成します
+//
+//	go generate ./assets
+//
+// スプライトは白黒で描き、描画時に定義ファイルの色を掛けて色を付けます
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"os"
+)
+
+// 1コマの大きさ
+const frameSize = 32
+
+// sprite はシートの1行分のアニメーションです
+type sprite struct {
+	Name   string  `json:"name"`
+	Row    int     `json:"row"`
+	Frames int     `json:"frames"`
+	FPS    float64 `json:"fps"`
+
+	draw func(c *canvas, frame int) `json:"-"`
+}
+
+type sheet struct {
+	FrameSize int      `json:"frame_size"`
+	Sprites   []sprite `json:"sprites"`
+}
+
+func main() {
+	sprites := []sprite{
+		{Name: "player", Frames: 4, FPS: 8, draw: drawPlayer},
+		{Name: "enemy/normal", Frames: 2, FPS: 4, draw: drawBlob},
+		{Name: "enemy/fast", Frames: 2, FPS: 8, draw: drawBat},
+		{Name: "enemy/tank", Frames: 2, FPS: 2, draw: drawTank},
+		{Name: "enemy/archer", Frames: 2, FPS: 3, draw: drawArcher},
+		{Name: "enemy/charger", Frames: 2, FPS: 6, draw: drawCharger},
+		{Name: "enemy/slime", Frames: 2, FPS: 3, draw: drawSlime},
+		{Name: "enemy/slime_small", Frames: 2, FPS: 5, draw: drawSlime},
+		{Name: "enemy/boss", Frames: 2, FPS: 2, draw: drawBoss},
+		{Name: "projectile", Frames: 2, FPS: 12, draw: drawOrb},
+		{Name: "bullet", Frames: 2, FPS: 12, draw: drawOrb},
+		{Name: "pickup/exp", Frames: 2, FPS: 4, draw: drawGem},
+		{Name: "pickup/heal", Frames: 1, draw: drawHeart},
+		{Name: "pickup/magnet", Frames: 1, draw: drawMagnet},
+	}
+
+	maxFrames := 0
+	for _, s := range sprites {
+		maxFrames = max(maxFrames, s.Frames)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, maxFrames*frameSize, len(sprites)*frameSize))
+	for row := range sprites {
+		s := &sprites[row]
+		s.Row = row
+		for f := 0; f < s.Frames; f++ {
+			c := &canvas{img: img, ox: f * frameSize, oy: row * frameSize}
+			s.draw(c, f)
+		}
+	}
+
+	out, err := os.Create("sprites.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := png.Encode(out, img); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(sheet{FrameSize: frameSize, Sprites: sprites}, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("sprites.json", append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// canvas はシートの1コマに描くための座標変換です
+type canvas struct {
+	img    *image.NRGBA
+	ox, oy int
+}
+
+// 明るさの段階
+const (
+	outline = 64
+	shade   = 176
+	light   = 255
+)
+
+func (c *canvas) set(x, y int, v uint8) {
+	if x < 0 || y < 0 || x >= frameSize || y >= frameSize {
+		return
+	}
+	c.img.SetNRGBA(c.ox+x, c.oy+y, color.NRGBA{v, v, v, 255})
+}
+
+// ellipse は (cx, cy) を中心に半径 (rx, ry) の楕円を輪郭付きで塗ります
+func (c *canvas) ellipse(cx, cy, rx, ry float64, v uint8) {
+	for y := 0; y < frameSize; y++ {
+		for x := 0; x < frameSize; x++ {
+			dx := (float64(x) + 0.5 - cx) / rx
+			dy := (float64(y) + 0.5 - cy) / ry
+			d := dx*dx + dy*dy
+			switch {
+			case d <= 0.7:
+				c.set(x, y, v)
+			case d <= 1:
+				c.set(x, y, min(v, shade))
+			case d <= 1.3:
+				c.set(x, y, outline)
+			}
+		}
+	}
+}
+
+// rect は (x0, y0) から (x1, y1) の手前までの四角形を輪郭付きで塗ります
+func (c *canvas) rect(x0, y0, x1, y1 int, v uint8) {
+	for y := y0 - 1; y <= y1; y++ {
+		for x := x0 - 1; x <= x1; x++ {
+			if x == x0-1 || y == y0-1 || x == x1 || y == y1 {
+				c.set(x, y, outline)
+			} else {
+				c.set(x, y, v)
+			}
+		}
+	}
+}
+
+func (c *canvas) eyes(y int, gap int) {
+	c.rect(16-gap-2, y, 16-gap, y+3, outline)
+	c.rect(16+gap, y, 16+gap+2, y+3, outline)
+}
+
+func drawPlayer(c *canvas, f int) {
+	bob := []float64{0, -1, 0, 1}[f]
+	c.ellipse(16, 9+bob, 6, 6, light)
+	c.rect(10, 15+int(bob), 22, 25, shade)
+	// 足を交互に出す
+	step := []int{0, 2, 0, -2}[f]
+	c.rect(11+step, 25, 14+step, 30, light)
+	c.rect(18-step, 25, 21-step, 30, light)
+	c.eyes(8+int(bob), 2)
+}
+
+func drawBlob(c *canvas, f int) {
+	c.ellipse(16, 17+float64(f), 12, 11-float64(f), light)
+	c.eyes(13+f, 3)
+}
+
+func drawBat(c *canvas, f int) {
+	wing := 12.0
+	if f == 1 {
+		wing = 7
+	}
+	c.ellipse(7, 14, 6, wing/2, shade)
+	c.ellipse(25, 14, 6, wing/2, shade)
+	c.ellipse(16, 16, 6, 7, light)
+	c.eyes(14, 1)
+}
+
+func drawTank(c *canvas, f int) {
+	c.rect(4, 6+f, 28, 28, light)
+	c.rect(8, 10+f, 24, 14+f, shade)
+	c.eyes(18+f, 4)
+}
+
+func drawArcher(c *canvas, f int) {
+	c.ellipse(14, 9, 5, 5, light)
+	c.rect(10, 14, 18, 27, shade)
+	// 弓を引く
+	for y := 6; y <= 26; y++ {
+		x := 24 + int(3*math.Sin(float64(y-6)/20*math.Pi)) - f
+		c.set(x, y, light)
+		c.set(x+1, y, outline)
+	}
+	c.eyes(8, 1)
+}
+
+func drawCharger(c *canvas, f int) {
+	// 角
+	c.rect(4+f, 4, 8+f, 12, shade)
+	c.rect(24-f, 4, 28-f, 12, shade)
+	c.ellipse(16, 18, 12, 9, light)
+	c.eyes(16, 4)
+}
+
+func drawSlime(c *canvas, f int) {
+	squish := float64(f) * 2
+	c.ellipse(16, 20+squish/2, 13+squish, 10-squish, light)
+	c.ellipse(12, 16, 3, 2, light)
+	c.eyes(19, 4)
+}
+
+func drawBoss(c *canvas, f int) {
+	c.ellipse(16, 15, 14, 13, light)
+	c.rect(9, 24, 23, 30, shade)
+	// 目が光る
+	eye := uint8(outline)
+	if f == 1 {
+		eye = light
+	}
+	c.ellipse(10, 14, 3, 3, eye)
+	c.ellipse(22, 14, 3, 3, eye)
+	for x := 11; x <= 21; x += 3 {
+		c.rect(x, 25, x+2, 28, light)
+	}
+}
+
+func drawOrb(c *canvas, f int) {
+	r := 12.0 - float64(f)*2
+	c.ellipse(16, 16, r, r, light)
+	c.ellipse(13, 13, r/3, r/3, light)
+}
+
+func drawGem(c *canvas, f int) {
+	for y := 4; y < 28; y++ {
+		half := 12 - abs(y-16)
+		for x := 16 - half; x <= 16+half; x++ {
+			v := uint8(shade)
+			if x < 16 || (f == 1 && x < 20) {
+				v = light
+			}
+			if x == 16-half || x == 16+half {
+				v = outline
+			}
+			c.set(x, y, v)
+		}
+	}
+}
+
+func drawHeart(c *canvas, _ int) {
+	c.ellipse(11, 12, 7, 7, light)
+	c.ellipse(21, 12, 7, 7, light)
+	for y := 14; y < 29; y++ {
+		half := 14 - (y - 14)
+		for x := 16 - half; x <= 16+half; x++ {
+			v := uint8(light)
+			if x == 16-half || x == 16+half || y == 28 {
+				v = outline
+			}
+			c.set(x, y, v)
+		}
+	}
+}
+
+func drawMagnet(c *canvas, _ int) {
+	c.rect(6, 6, 12, 26, light)
+	c.rect(20, 6, 26, 26, light)
+	c.rect(6, 20, 26, 26, light)
+	c.rect(6, 6, 12, 10, shade)
+	c.rect(20, 6, 26, 10, shade)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,20 @@
+// Package atomicfile はファイルを書き込みの途中の状態で残さないように書き込みます
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write は data を path に書き込みます。ディレクトリがなければ作成します
+// 書き込みの途中で終了しても壊れたファイルが残らないよう、別名で書いてから置き換えます
+func Write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+
+	"vampire-survivors-like/world"
+)
+
+// オプション画面で音量を変える刻み
+const volumeStep = 0.1
+
+// playEvents は直前のティックで起きた出来事に合わせて効果音を鳴らします
+func (g *Game) playEvents() {
+	for _, e := range g.world.Events() {
+		switch e.Kind {
+		case world.EventWeaponFire:
+			g.sound.Play(g.weaponSound(e.Weapon))
+		case world.EventEnemyDeath:
+			if e.Boss && g.sound.Has("boss_death") {
+				g.sound.Play("boss_death")
+			} else {
+				g.sound.Play("enemy_death")
+			}
+		case world.EventLevelUp:
+			g.sound.Play("level_up")
+		case world.EventPlayerHit:
+			g.sound.Play("player_hit")
+		}
+	}
+}
+
+// weaponSound は武器の攻撃の効果音の名前を返します
+// 武器の id の効果音がなければ、武器の種類（behavior）の効果音を使います
+func (g *Game) weaponSound(id world.WeaponType) string {
+	if name := "weapon/" + string(id); g.sound.Has(name) {
+		return name
+	}
+	params, _ := g.world.Definitions().Weapon(id)
+	return "weapon/" + string(params.Behavior)
+}
+
+// stageMusic はゲーム中の BGM の名前を返します。ボスがいる間はボス戦の曲にします
+func (g *Game) stageMusic() string {
+	for _, e := range g.world.Enemies {
+		if e.Boss {
+			return "boss"
+		}
+	}
+	return "stage"
+}
+
+// setMusicVolume は BGM の音量を変えます
+func (g *Game) setMusicVolume(v float64) {
+	g.musicVolume = roundVolume(v)
+	g.sound.SetMusicVolume(g.musicVolume)
+}
+
+// setSFXVolume は効果音の音量を変え、確認のために効果音を鳴らします
+func (g *Game) setSFXVolume(v float64) {
+	g.sfxVolume = roundVolume(v)
+	g.sound.SetSFXVolume(g.sfxVolume)
+	g.sound.Play("enemy_death")
+}
+
+// roundVolume は音量を 0〜1 の volumeStep 刻みに丸めます
+func roundVolume(v float64) float64 {
+	return min(max(math.Round(v/volumeStep)*volumeStep, 0), 1)
+}
+
+// nextVolume は決定を押したときの次の音量です。最大の次は 0 に戻ります
+func nextVolume(v float64) float64 {
+	if v >= 1 {
+		return 0
+	}
+	return v + volumeStep
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"cmp"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/ui"
+	"vampire-survivors-like/world"
+)
+
+// 条件を満たしていないキャラクターの色
+var lockedColor = color.RGBA{110, 110, 110, 255}
+
+// characterScene はゲームを始める前にキャラクターを選ぶ画面です。閉じると back の scene に戻ります
+// 選んだキャラクターは記録に残し、次に開いたときも同じキャラクターから選び始めます
+type characterScene struct {
+	back scene
+	menu menu
+}
+
+func (s *characterScene) enter(g *Game) {
+	chars := g.world.NextDefinitions().Characters
+	s.menu.cursor = max(0, slices.IndexFunc(chars, func(c world.CharacterParams) bool {
+		return c.CharacterType == g.records.Character
+	}))
+}
+
+func (*characterScene) exit(*Game) {}
+
+func (s *characterScene) update(g *Game) {
+	if g.controls.JustPressed(ActionCancel) {
+		g.changeScene(s.back)
+		return
+	}
+	chars := g.world.NextDefinitions().Characters
+	i := s.menu.update(g.controls, len(chars))
+	if i < 0 || !g.records.IsUnlocked(chars[i]) {
+		return
+	}
+	g.records.Character = chars[i].CharacterType
+	g.saveRecords()
+	g.startRun()
+}
+
+func (s *characterScene) draw(g *Game, screen *ebiten.Image) {
+	screen.Fill(color.RGBA{16, 20, 16, 255})
+	cx := float64(world.ScreenWidth) / 2
+	ui.Centered(screen, g.text.T(i18n.CharactersTitle), cx, 50, ui.TextOptions{Scale: 2, Outline: outlineColor})
+
+	defs := g.world.NextDefinitions()
+	const top, spacing, left = 120, 40, 80
+	for i, c := range defs.Characters {
+		y := float64(top + i*spacing)
+		opts := ui.TextOptions{}
+		clr := color.RGBA(c.Color)
+		switch {
+		case !g.records.IsUnlocked(c):
+			opts.Color = lockedColor
+			clr = lockedColor
+		case i == s.menu.cursor:
+			opts.Color = selectedColor
+		}
+		if i == s.menu.cursor {
+			fillRect(screen, left-8, y-8, 220, 32, color.RGBA{255, 255, 255, 24})
+		}
+		g.drawSprite(screen, "player", left+8, y+8, 24, clr)
+		ui.Text(screen, g.characterName(c), left+32, y, opts)
+	}
+
+	// 選んでいるキャラクターの詳しい説明
+	c := defs.Characters[s.menu.cursor]
+	base := c.Base(defs.Player)
+	weapon, _ := defs.Weapon(base.Weapon)
+	const x, width = 340, world.ScreenWidth - 340 - 60
+	type line struct {
+		text string
+		clr  color.Color
+	}
+	lines := []line{
+		{g.text.Or(i18n.CharacterDescription(c.CharacterType), c.Description), nil},
+		{g.text.T(i18n.CharactersWeapon, g.text.Or(i18n.WeaponName(weapon.WeaponType), cmp.Or(weapon.Name, string(weapon.WeaponType)))), nil},
+		{g.text.T(i18n.CharactersStats, base.HP, base.Speed), nil},
+		{g.text.T(i18n.CharactersPassive, g.text.Or(i18n.CharacterPassiveName(c.CharacterType), c.Passive.Name)), selectedColor},
+		{g.text.Or(i18n.CharacterPassiveDescription(c.CharacterType), c.Passive.Description), hintColor},
+	}
+	if !g.records.IsUnlocked(c) {
+		lines = append(lines, line{g.text.T(i18n.CharactersLocked) + "  " + g.unlockText(*c.Unlock), color.RGBA{255, 80, 80, 255}})
+	}
+	ui.Text(screen, g.characterName(c), x, top, ui.TextOptions{Scale: 2, Color: color.RGBA(c.Color), Outline: outlineColor})
+	y := float64(top + 50)
+	for _, line := range lines {
+		opts := ui.TextOptions{Color: line.clr, Width: width}
+		ui.Text(screen, line.text, x, y, opts)
+		_, h := ui.Measure(line.text, opts)
+		y += h + 12
+	}
+
+	ui.Centered(screen, g.text.T(i18n.CharactersHint), cx, world.ScreenHeight-40, ui.TextOptions{Color: hintColor})
+}
+
+// characterName はキャラクターの名前を現在の言語で返します
+func (g *Game) characterName(c world.CharacterParams) string {
+	return g.text.Or(i18n.CharacterName(c.CharacterType), cmp.Or(c.Name, string(c.CharacterType)))
+}
+
+// unlockText はキャラクターを選べるようになる条件を現在の言語で返します
+func (g *Game) unlockText(cond world.UnlockCondition) string {
+	value := int(cond.Value)
+	switch cond.Kind {
+	case world.UnlockSurvival:
+		return g.text.T(i18n.UnlockSurvival, value/60, value%60)
+	case world.UnlockScore:
+		return g.text.T(i18n.UnlockScore, value)
+	case world.UnlockLevel:
+		return g.text.T(i18n.UnlockLevel, value)
+	case world.UnlockKills:
+		if cond.Enemy == "" {
+			return g.text.T(i18n.UnlockKillsTotal, value)
+		}
+		return g.text.T(i18n.UnlockKills, g.enemyName(cond.Enemy), value)
+	case world.UnlockRuns:
+		return g.text.T(i18n.UnlockRuns, value)
+	}
+	return string(cond.Kind)
+}
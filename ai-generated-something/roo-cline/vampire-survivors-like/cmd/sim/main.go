@@ -0,0 +1,135 @@
+// sim はウィンドウなしでゲームを繰り返し実行し、プレイごとの統計を出力します
+//
+//	go run ./cmd/sim -runs 1000 -policy kite > runs.jsonl
+//
+// 標準出力には1プレイ1行の JSON を、標準エラー出力には全体の集計を出力します
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+
+	"vampire-survivors-like/world"
+)
+
+// runResult は1プレイの結果です
+type runResult struct {
+	Run  int   `json:"run"`
+	Seed int64 `json:"seed"`
+	world.Stats
+}
+
+func main() {
+	runs := flag.Int("runs", 100, "実行するプレイ数")
+	seed := flag.Int64("seed", 1, "最初のプレイのシード（以降は1ずつ増やす）")
+	policyName := flag.String("policy", "kite", "入力のポリシー (idle, random, kite)")
+	maxTime := flag.Float64("max-time", 30*60, "1プレイの最大時間（秒）")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "同時に実行するプレイ数")
+	defsPath := flag.String("defs", "", "武器・敵・スキルの定義ファイルのパス（省略時は組み込みの定義）")
+	stage := flag.String("stage", "", "遊ぶステージの id（省略時は定義の最初のステージ）")
+	character := flag.String("character", "", "使うキャラクターの id（省略時は定義の最初のキャラクター）")
+	flag.Parse()
+
+	defs := world.DefaultDefinitions()
+	if *defsPath != "" {
+		var err error
+		defs, err = world.LoadDefinitions(*defsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if _, ok := defs.Stage(*stage); *stage != "" && !ok {
+		log.Fatalf("unknown stage %q", *stage)
+	}
+	if _, ok := defs.Character(world.CharacterType(*character)); *character != "" && !ok {
+		log.Fatalf("unknown character %q", *character)
+	}
+
+	// ポリシー名の誤りは実行前に報告する
+	if _, err := newPolicy(*policyName, 0); err != nil {
+		log.Fatal(err)
+	}
+
+	results := make([]runResult, *runs)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < *parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range jobs {
+				s := *seed + int64(run)
+				policy, _ := newPolicy(*policyName, s)
+				results[run] = runResult{
+					Run:   run,
+					Seed:  s,
+					Stats: simulate(world.Config{Seed: s, Defs: defs, Stage: *stage, Character: world.CharacterType(*character)}, policy, *maxTime),
+				}
+			}
+		}()
+	}
+	for run := 0; run < *runs; run++ {
+		jobs <- run
+	}
+	close(jobs)
+	wg.Wait()
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			log.Fatal(err)
+		}
+	}
+	printSummary(os.Stderr, defs, results)
+}
+
+// simulate は1プレイをゲームオーバーか maxTime 秒まで実行します
+func simulate(cfg world.Config, policy Policy, maxTime float64) world.Stats {
+	g := world.NewGameWithConfig(cfg)
+	for !g.GameOver && g.Time() < maxTime {
+		g.Step(policy.Input(g))
+	}
+	return g.Stats()
+}
+
+// printSummary は全プレイの平均を w に出力します
+func printSummary(w io.Writer, defs *world.Definitions, results []runResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	var survival float64
+	var level, score int
+	kills := make(map[world.EnemyType]int)
+	damage := make(map[world.WeaponType]int)
+	for _, r := range results {
+		survival += r.SurvivalTime
+		level += r.Level
+		score += r.Score
+		for k, v := range r.Kills {
+			kills[k] += v
+		}
+		for k, v := range r.DamageDealt {
+			damage[k] += v
+		}
+	}
+
+	n := float64(len(results))
+	fmt.Fprintf(w, "runs: %d\n", len(results))
+	fmt.Fprintf(w, "avg survival: %.1fs  avg level: %.2f  avg score: %.1f\n", survival/n, float64(level)/n, float64(score)/n)
+	fmt.Fprintln(w, "avg kills:")
+	for _, e := range defs.Enemies {
+		fmt.Fprintf(w, "  %-14s %10.1f\n", e.Type, float64(kills[e.Type])/n)
+	}
+	fmt.Fprintln(w, "avg damage dealt:")
+	for _, wp := range defs.Weapons {
+		fmt.Fprintf(w, "  %-14s %10.1f\n", wp.WeaponType, float64(damage[wp.WeaponType])/n)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"vampire-survivors-like/world"
+)
+
+// Policy はウィンドウなしでゲームを動かすときの入力を決めます
+type Policy interface {
+	Input(g *world.Game) world.Input
+}
+
+// newPolicy は name の Policy を作成します
+func newPolicy(name string, seed int64) (Policy, error) {
+	rng := rand.New(rand.NewSource(seed))
+	switch name {
+	case "idle":
+		return &idlePolicy{rng: rng}, nil
+	case "random":
+		return &randomPolicy{rng: rng}, nil
+	case "kite":
+		return &kitePolicy{rng: rng}, nil
+	}
+	return nil, fmt.Errorf("unknown policy %q (idle, random, kite)", name)
+}
+
+// chooseSkill はスキルの選択肢からランダムに1つ選びます
+func chooseSkill(g *world.Game, rng *rand.Rand) world.Input {
+	return world.Input{Choice: rng.Intn(len(g.SkillOptions)) + 1}
+}
+
+// idlePolicy はその場から動かずにスキルだけ選びます
+type idlePolicy struct {
+	rng *rand.Rand
+}
+
+func (p *idlePolicy) Input(g *world.Game) world.Input {
+	if g.ChoosingSkill {
+		return chooseSkill(g, p.rng)
+	}
+	return world.Input{}
+}
+
+// randomPolicy は一定間隔でランダムな方向に移動します
+type randomPolicy struct {
+	rng   *rand.Rand
+	ticks int
+	move  world.Input
+}
+
+func (p *randomPolicy) Input(g *world.Game) world.Input {
+	if g.ChoosingSkill {
+		return chooseSkill(g, p.rng)
+	}
+	if p.ticks%world.TicksPerSecond == 0 {
+		// 上下・左右をそれぞれ独立に押すかどうか決める（両方押すと止まる）
+		up, down := p.rng.Intn(2), p.rng.Intn(2)
+		left, right := p.rng.Intn(2), p.rng.Intn(2)
+		p.move = world.Input{
+			MoveX: float64(right - left),
+			MoveY: float64(down - up),
+		}
+	}
+	p.ticks++
+	return p.move
+}
+
+// kitePolicy は kiteRadius 以内の敵から近いものほど強く離れ、いちばん近いアイテムに寄りながら逃げ回ります
+// フィールドに端はなくカメラが追いかけるので、決まった場所には留まりません
+type kitePolicy struct {
+	rng *rand.Rand
+}
+
+// 逃げる対象にする敵との距離
+const kiteRadius = 200.0
+
+func (p *kitePolicy) Input(g *world.Game) world.Input {
+	if g.ChoosingSkill {
+		return chooseSkill(g, p.rng)
+	}
+
+	// 近い敵ほど強く反発する方向を求める
+	var vx, vy float64
+	for _, enemy := range g.Enemies {
+		dx := g.Player.X - enemy.X
+		dy := g.Player.Y - enemy.Y
+		dist := math.Sqrt(dx*dx + dy*dy)
+		if dist == 0 || dist > kiteRadius {
+			continue
+		}
+		w := (kiteRadius - dist) / kiteRadius
+		vx += dx / dist * w
+		vy += dy / dist * w
+	}
+
+	// 最も近いアイテムを拾いに行く
+	if pickup := nearestPickup(g); pickup != nil {
+		dx := pickup.X - g.Player.X
+		dy := pickup.Y - g.Player.Y
+		if dist := math.Sqrt(dx*dx + dy*dy); dist > 0 {
+			vx += dx / dist * 0.5
+			vy += dy / dist * 0.5
+		}
+	}
+
+	// 反発が弱いときは動かず、それ以外は全速で動く
+	const deadZone = 0.1
+	l := math.Hypot(vx, vy)
+	if l < deadZone {
+		return world.Input{}
+	}
+	return world.Input{MoveX: vx / l, MoveY: vy / l}
+}
+
+// nearestPickup はプレイヤーに最も近いアイテムを返します
+func nearestPickup(g *world.Game) *world.Pickup {
+	var nearest *world.Pickup
+	nearestDist := math.MaxFloat64
+	for _, p := range g.Pickups {
+		dx := p.X - g.Player.X
+		dy := p.Y - g.Player.Y
+		if dist := math.Sqrt(dx*dx + dy*dy); dist < nearestDist {
+			nearestDist = dist
+			nearest = p
+		}
+	}
+	return nearest
+}
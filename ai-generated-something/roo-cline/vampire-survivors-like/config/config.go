@@ -0,0 +1,61 @@
+// Package config はプレイヤーの設定（言語やキー割り当てなど）をファイルに保存します
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"vampire-survivors-like/atomicfile"
+)
+
+// Config はプレイヤーの設定です。空の項目は既定値を使います
+type Config struct {
+	Lang string              `json:"lang,omitempty"` // 表示する言語
+	Keys map[string][]string `json:"keys,omitempty"` // 操作の名前ごとに割り当てたキーの名前
+
+	// 音量 (0〜1)。0 と区別するため、設定していなければ nil にする
+	MusicVolume *float64 `json:"music_volume,omitempty"`
+	SFXVolume   *float64 `json:"sfx_volume,omitempty"`
+
+	ReducedEffects bool `json:"reduced_effects,omitempty"` // パーティクルなどの演出を減らす
+}
+
+// DefaultPath は設定ファイルの既定の場所を返します
+// ユーザーの設定ディレクトリがない環境（ブラウザなど）ではエラーを返します
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vampire-survivors-like", "config.json"), nil
+}
+
+// Load はファイルから設定を読み込みます
+// ファイルがなければ空の設定を返します
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save は設定をファイルに保存します。ディレクトリがなければ作成します
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, append(data, '\n'))
+}
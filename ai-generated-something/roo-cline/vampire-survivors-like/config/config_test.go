@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "config.json")
+
+	// ファイルがなければ空の設定になる
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, &Config{}) {
+		t.Fatalf("Load(missing) = %+v, want empty", c)
+	}
+
+	c.Lang = "en"
+	c.Keys = map[string][]string{"up": {"ArrowUp", "W"}}
+	// 音量の 0 は未設定と区別して保存される
+	music, sfx := 0.0, 0.5
+	c.MusicVolume, c.SFXVolume = &music, &sfx
+	c.ReducedEffects = true
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("Load() = %+v, want %+v", got, c)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load(invalid JSON) succeeded, want error")
+	}
+}
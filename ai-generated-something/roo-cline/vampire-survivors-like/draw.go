@@ -0,0 +1,267 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/ui"
+	"vampire-survivors-like/world"
+)
+
+// 文字の色
+var (
+	outlineColor = color.RGBA{0, 0, 0, 255}       // 縁取り
+	hintColor    = color.RGBA{180, 180, 180, 255} // 操作の説明
+)
+
+// Draw は毎フレーム画面を描画します
+// 画像はすべて起動時に読み込んだものを使い、フレームごとには作りません
+func (g *Game) Draw(screen *ebiten.Image) {
+	g.scene.draw(g, screen)
+}
+
+// drawSkillSelection はレベルアップ時のスキル選択画面を描画します
+// cursor 番目の選択肢を強調します（-1 なら強調しない）
+func (g *Game) drawSkillSelection(screen *ebiten.Image, cursor int) {
+	fillRect(screen, 0, 0, world.ScreenWidth, world.ScreenHeight, color.RGBA{0, 0, 0, 200})
+
+	// タイトルテキストを描画
+	title := g.text.T(i18n.LevelUpTitle)
+	if g.world.PendingLevelUps > 1 {
+		title += g.text.N(i18n.LevelUpRemaining, g.world.PendingLevelUps, g.world.PendingLevelUps)
+	}
+	ui.Centered(screen, title, world.ScreenWidth/2, world.ScreenHeight/2-120, ui.TextOptions{Outline: outlineColor})
+
+	for i, skill := range g.world.SkillOptions {
+		// スキル選択ボタンの背景
+		const buttonWidth, padding = 400, 20
+		button := color.RGBA{50, 50, 50, 255}
+		if i == cursor {
+			button = color.RGBA{90, 80, 40, 255}
+		}
+		fillRect(screen, world.ScreenWidth/2-buttonWidth/2, float64(world.ScreenHeight/2-75+i*60), buttonWidth, 50, button)
+
+		// スキルの説明は長ければボタンの幅で折り返す
+		text := g.text.T(i18n.SkillOption, i+1, g.skillText(skill))
+		ui.Text(screen, text, world.ScreenWidth/2-buttonWidth/2+padding, float64(world.ScreenHeight/2-67+i*60),
+			ui.TextOptions{Width: buttonWidth - padding*2})
+	}
+
+	// このプレイで残っている引き直し・飛ばし・除外の回数
+	actions := g.world.SkillActions
+	hint := g.text.T(i18n.LevelUpActions,
+		g.controls.KeyName(ActionReroll), actions.Rerolls,
+		g.controls.KeyName(ActionSkip), actions.Skips,
+		g.controls.KeyName(ActionBanish), actions.Banishes)
+	ui.Centered(screen, hint, world.ScreenWidth/2, float64(world.ScreenHeight/2-75+len(g.world.SkillOptions)*60+20),
+		ui.TextOptions{Color: hintColor, Width: world.ScreenWidth - 80})
+}
+
+// drawWorld はゲーム中の画面と HUD を描画します
+func (g *Game) drawWorld(screen *ebiten.Image) {
+	// ワールドの座標はカメラからの相対位置で描画する
+	// 画面の揺れはカメラをずらして表す
+	cam := g.world.Camera()
+	shakeX, shakeY := g.fx.Offset()
+	cam.X += shakeX
+	cam.Y += shakeY
+	drawBackground(screen, cam)
+
+	// 武器の攻撃範囲の描画 (パッシブアイテムによる補正込み)
+	player := g.world.Player
+	for _, weapon := range player.Weapons {
+		attackRange := player.EffectiveWeapon(weapon.Params).AttackRange
+		rangeColor := weapon.Params.Color
+		vector.DrawFilledCircle(screen, float32(player.X-cam.X), float32(player.Y-cam.Y), float32(attackRange),
+			color.RGBA{rangeColor.R, rangeColor.G, rangeColor.B, 64}, true)
+	}
+
+	// スプライトは同じシートから続けて描くほどまとめて描画される
+	// アイテムの描画
+	defs := g.world.Definitions()
+	for _, pickup := range g.world.Pickups {
+		size := 12.0
+		if pickup.Kind != world.PickupExp {
+			size = 16
+		}
+		if cam.Contains(pickup.X, pickup.Y, size) {
+			g.drawSprite(screen, "pickup/"+string(pickup.Kind), pickup.X-cam.X, pickup.Y-cam.Y, size, color.RGBA(defs.PickupColor(pickup)))
+		}
+	}
+
+	// 敵の描画
+	for _, enemy := range g.world.Enemies {
+		// 画面に映らない敵は描画しない
+		if !cam.Contains(enemy.X, enemy.Y, enemy.Size) {
+			continue
+		}
+		// 敵の種類に応じた色は定義ファイルで設定する
+		enemyColor := color.RGBA(enemy.Color)
+		if enemy.Telegraphing() && int(g.world.Time()*10)%2 == 0 {
+			// 突進の構えは点滅で知らせる
+			enemyColor = color.RGBA{255, 255, 255, 255}
+		}
+		g.drawSprite(screen, "enemy/"+string(enemy.Type), enemy.X-cam.X, enemy.Y-cam.Y, enemy.Size, enemyColor)
+	}
+
+	// プレイヤーの描画（無敵の間は点滅させる）
+	if !player.Invulnerable() || int(g.world.Time()*15)%2 == 0 {
+		g.drawSprite(screen, "player", player.X-cam.X, player.Y-cam.Y, 32, g.playerColor())
+	}
+
+	// 弾の描画
+	for _, weapon := range player.Weapons {
+		for _, proj := range weapon.Projectiles {
+			if cam.Contains(proj.X, proj.Y, 4) {
+				g.drawSprite(screen, "projectile", proj.X-cam.X, proj.Y-cam.Y, 8, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+	for _, bullet := range g.world.EnemyBullets {
+		if cam.Contains(bullet.X, bullet.Y, 4) {
+			g.drawSprite(screen, "bullet", bullet.X-cam.X, bullet.Y-cam.Y, 8, color.RGBA{255, 80, 200, 255})
+		}
+	}
+
+	g.drawParticles(screen, cam)
+
+	// 敵のHPバーの描画
+	for _, enemy := range g.world.Enemies {
+		if enemy.HP >= enemy.MaxHP || !cam.Contains(enemy.X, enemy.Y, enemy.Size) {
+			continue
+		}
+		x := enemy.X - enemy.Size/2 - cam.X
+		y := enemy.Y - enemy.Size/2 - 8 - cam.Y
+		fillRect(screen, x, y, enemy.Size, 4, color.RGBA{100, 100, 100, 255})
+		if enemy.HP > 0 {
+			fillRect(screen, x, y, enemy.Size*float64(enemy.HP)/float64(enemy.MaxHP), 4, color.RGBA{255, 0, 0, 255})
+		}
+	}
+
+	// 画面の外にいるボスの方向を矢印で示す
+	for _, enemy := range g.world.Enemies {
+		if enemy.Boss && !cam.Contains(enemy.X, enemy.Y, 0) {
+			drawBossIndicator(screen, enemy.X-cam.X, enemy.Y-cam.Y, color.RGBA(enemy.Color))
+		}
+	}
+
+	g.drawDamageNumbers(screen, cam)
+
+	// HPバーの描画
+	const barWidth, barHeight = 200.0, 20.0
+	fillRect(screen, 10, 10, barWidth, barHeight, color.RGBA{100, 100, 100, 255})
+	if player.HP > 0 {
+		fillRect(screen, 10, 10, barWidth*float64(player.HP)/float64(player.MaxHP), barHeight, color.RGBA{0, 255, 0, 255})
+	}
+
+	// 経験値バーの描画
+	expBarY := 10 + barHeight + 5
+	fillRect(screen, 10, expBarY, barWidth, barHeight, color.RGBA{50, 50, 100, 255})
+	fillRect(screen, 10, expBarY, barWidth*float64(player.Exp)/float64(player.ExpToNextLevel), barHeight, color.RGBA{0, 0, 255, 255})
+
+	// レベルとスコアの表示
+	// HUD の文字は地面の上でも読めるように縁取りする
+	hud := ui.TextOptions{Outline: outlineColor}
+	levelText := g.text.T(i18n.HUDLevelScore, player.Level, g.world.Score)
+	ui.Text(screen, levelText, 10, 50, hud)
+
+	// 経過時間の表示
+	timeText := g.text.T(i18n.HUDTime, g.world.Time())
+	ui.Text(screen, timeText, 10, 70, hud)
+
+	// 所持しているパッシブアイテムの一覧
+	for i, passive := range player.Passives {
+		passiveText := g.text.T(i18n.HUDPassive, g.passiveName(passive.Params), passive.Level)
+		ui.Text(screen, passiveText, 10, float64(100+i*16), hud)
+	}
+
+	// ボスの出現などの警告
+	if warning := g.world.Warning(); warning != "" {
+		// 警告の文言はメッセージカタログの ID でも直接の文言でもよい
+		ui.Centered(screen, g.text.Or(warning, warning), world.ScreenWidth/2, 88,
+			ui.TextOptions{Scale: 2, Color: color.RGBA{255, 80, 80, 255}, Outline: outlineColor})
+	}
+
+	// 一時停止の仕方
+	ui.Text(screen, g.text.T(i18n.HUDPause), world.ScreenWidth-10, world.ScreenHeight-26,
+		ui.TextOptions{Align: ui.AlignEnd, Color: hintColor, Outline: outlineColor})
+}
+
+// playerColor はキャラクターの色を返します。定義に色がなければ青にします
+func (g *Game) playerColor() color.RGBA {
+	if c := color.RGBA(g.world.Character().Color); c.A > 0 {
+		return c
+	}
+	return color.RGBA{64, 128, 255, 255}
+}
+
+// drawSprite は name のスプライトを画面の (x, y) を中心に size の大きさで描画します
+// スプライトは白黒で描かれているので clr を掛けて色を付けます
+// スプライトがなければ clr で塗った四角形で代用します
+func (g *Game) drawSprite(screen *ebiten.Image, name string, x, y, size float64, clr color.Color) {
+	sprite := g.assets.Sprite(name)
+	if sprite == nil {
+		fillRect(screen, x-size/2, y-size/2, size, size, clr)
+		return
+	}
+	frame := sprite.Frame(g.world.Time())
+	scale := size / float64(frame.Bounds().Dx())
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x-size/2, y-size/2)
+	op.ColorScale.ScaleWithColor(clr)
+	screen.DrawImage(frame, op)
+}
+
+// fillRect は単色の四角形を描画します
+func fillRect(screen *ebiten.Image, x, y, width, height float64, clr color.Color) {
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(width), float32(height), clr, false)
+}
+
+// 背景のタイルの大きさ
+const tileSize = 64
+
+// drawBackground はカメラの位置に合わせて市松模様の地面を描画します
+// ワールドには端がないので、画面に映る範囲のタイルだけを描きます
+func drawBackground(screen *ebiten.Image, cam world.Camera) {
+	screen.Fill(color.RGBA{24, 32, 24, 255})
+	startX := int(math.Floor(cam.X / tileSize))
+	startY := int(math.Floor(cam.Y / tileSize))
+	for ty := startY; float64(ty*tileSize) < cam.Y+world.ScreenHeight; ty++ {
+		for tx := startX; float64(tx*tileSize) < cam.X+world.ScreenWidth; tx++ {
+			if (tx+ty)%2 == 0 {
+				continue
+			}
+			x := float64(tx*tileSize) - cam.X
+			y := float64(ty*tileSize) - cam.Y
+			fillRect(screen, x, y, tileSize, tileSize, color.RGBA{32, 44, 32, 255})
+		}
+	}
+}
+
+// drawBossIndicator は画面の外にいるボスの方向を指す矢印を画面の端に描画します
+// (x, y) はボスの画面上の座標です
+func drawBossIndicator(screen *ebiten.Image, x, y float64, clr color.Color) {
+	const inset = 24
+	cx, cy := float64(world.ScreenWidth)/2, float64(world.ScreenHeight)/2
+	dx, dy := x-cx, y-cy
+
+	// 画面の中央からボスへの線が、端から inset 内側の枠と交わる点に矢印の先を置く
+	scale := math.Min((cx-inset)/math.Abs(dx), (cy-inset)/math.Abs(dy))
+	tipX, tipY := cx+dx*scale, cy+dy*scale
+
+	angle := math.Atan2(dy, dx)
+	const length, head = 20.0, 8.0
+	tailX := tipX - math.Cos(angle)*length
+	tailY := tipY - math.Sin(angle)*length
+	vector.StrokeLine(screen, float32(tailX), float32(tailY), float32(tipX), float32(tipY), 3, clr, true)
+	for _, side := range []float64{-1, 1} {
+		a := angle + math.Pi - side*math.Pi/6
+		vector.StrokeLine(screen, float32(tipX), float32(tipY),
+			float32(tipX+math.Cos(a)*head), float32(tipY+math.Sin(a)*head), 3, clr, true)
+	}
+}
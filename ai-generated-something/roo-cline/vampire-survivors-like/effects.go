@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"vampire-survivors-like/ui"
+	"vampire-survivors-like/world"
+)
+
+// ダメージの数字の色
+var (
+	enemyDamageColor  = world.Color{R: 255, G: 255, B: 255, A: 255} // 敵が受けたダメージ
+	playerDamageColor = world.Color{R: 255, G: 80, B: 80, A: 255}   // プレイヤーが受けたダメージ
+)
+
+// 画面の揺れの強さ（ピクセル）
+const (
+	bossHitShake   = 3
+	bossDeathShake = 12
+)
+
+// updateEffects は直前のティックで起きた出来事とゲームの状態から演出を出し、演出を1ティック進めます
+func (g *Game) updateEffects() {
+	defs := g.world.Definitions()
+	for _, e := range g.world.Events() {
+		switch e.Kind {
+		case world.EventEnemyHit:
+			g.fx.Spark(e.X, e.Y)
+			g.fx.Number(e.X, e.Y, e.Amount, enemyDamageColor)
+			if e.Boss {
+				g.fx.Shake(bossHitShake)
+			}
+		case world.EventEnemyDeath:
+			params, _ := defs.Enemy(e.Enemy)
+			if e.Boss {
+				g.fx.Burst(e.X, e.Y, params.Color, 80, 6)
+				g.fx.Shake(bossDeathShake)
+			} else {
+				g.fx.Burst(e.X, e.Y, params.Color, 12, 3)
+			}
+		case world.EventPlayerHit:
+			g.fx.Number(e.X, e.Y-20, e.Amount, playerDamageColor)
+		}
+	}
+
+	// オーラのきらめきと螺旋の弾の跡は、ゲームが進んでいる間だけ出し続ける
+	if !g.world.ChoosingSkill && !g.world.GameOver {
+		player := g.world.Player
+		for _, weapon := range player.Weapons {
+			switch weapon.Params.Behavior {
+			case world.BehaviorAura:
+				g.fx.Shimmer(player.X, player.Y, player.EffectiveWeapon(weapon.Params).AttackRange, weapon.Params.Color)
+			case world.BehaviorSpiral:
+				for _, proj := range weapon.Projectiles {
+					g.fx.Trail(proj.X, proj.Y, weapon.Params.Color)
+				}
+			}
+		}
+	}
+	g.fx.Update()
+}
+
+// drawParticles はパーティクルを描画します
+func (g *Game) drawParticles(screen *ebiten.Image, cam world.Camera) {
+	for _, p := range g.fx.Particles() {
+		if !cam.Contains(p.X, p.Y, p.Size) {
+			continue
+		}
+		fillRect(screen, p.X-p.Size/2-cam.X, p.Y-p.Size/2-cam.Y, p.Size, p.Size, fade(p.Color, p.Alpha()))
+	}
+}
+
+// drawDamageNumbers はダメージの数字を描画します
+func (g *Game) drawDamageNumbers(screen *ebiten.Image, cam world.Camera) {
+	for _, n := range g.fx.Numbers() {
+		if !cam.Contains(n.X, n.Y, 16) {
+			continue
+		}
+		ui.Text(screen, strconv.Itoa(n.Value), n.X-cam.X, n.Y-cam.Y, ui.TextOptions{
+			Align:   ui.AlignCenter,
+			Color:   fade(n.Color, n.Alpha()),
+			Outline: fade(world.Color(outlineColor), n.Alpha()),
+		})
+	}
+}
+
+// fade は clr の不透明度に alpha を掛けた色を返します
+func fade(clr world.Color, alpha float64) color.Color {
+	return color.NRGBA{clr.R, clr.G, clr.B, uint8(float64(clr.A) * alpha)}
+}
@@ -0,0 +1,248 @@
+// Package fx は見た目だけの演出（パーティクル・ダメージの数字・画面の揺れ）を管理します
+//
+// 演出はゲームの進行に影響しないので、world の乱数とは別の乱数を使います
+// パーティクルは起動時に確保した領域を使い回し、毎ティック新しく確保しません
+package fx
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"vampire-survivors-like/world"
+)
+
+// 同時に出せる数の上限
+const (
+	maxParticles        = 2048
+	maxParticlesReduced = 256 // 演出を減らしているとき
+	maxNumbers          = 64
+	maxNumbersReduced   = 16
+)
+
+// 減らしているときにバーストで出すパーティクルの割合
+const reducedRatio = 4
+
+// パーティクルの速度が1ティックごとに減る割合
+const drag = 0.92
+
+// 画面の揺れが1ティックごとに減る割合
+const shakeDecay = 0.85
+
+// Particle は1つのパーティクルです。Life が 0 になると消えます
+type Particle struct {
+	X, Y    float64
+	VX, VY  float64
+	Size    float64
+	Color   world.Color
+	Life    int // 残りのティック数
+	MaxLife int
+}
+
+// Alpha は残りの寿命に応じた不透明度 (0〜1) です
+func (p *Particle) Alpha() float64 {
+	return float64(p.Life) / float64(p.MaxLife)
+}
+
+// Number は浮かび上がって消えるダメージの数字です
+type Number struct {
+	X, Y    float64
+	Value   int
+	Color   world.Color
+	Life    int
+	MaxLife int
+}
+
+// Alpha は残りの寿命に応じた不透明度 (0〜1) です
+func (n *Number) Alpha() float64 {
+	return float64(n.Life) / float64(n.MaxLife)
+}
+
+// System はすべての演出を持ちます。Update をゲームの1ティックごとに呼んでください
+type System struct {
+	particles []Particle // 生きているパーティクル。容量が上限
+	numbers   []Number
+	shake     float64 // 画面の揺れの強さ（ピクセル）
+	reduced   bool
+	rng       *rand.Rand
+}
+
+// New は空の System を作成します
+func New() *System {
+	return &System{
+		particles: make([]Particle, 0, maxParticles),
+		numbers:   make([]Number, 0, maxNumbers),
+		rng:       rand.New(rand.NewPCG(1, 2)),
+	}
+}
+
+// SetReduced は演出を減らすかを設定します
+// 減らしているときはパーティクルと数字の上限を下げ、バーストの数も減らします
+func (s *System) SetReduced(reduced bool) {
+	s.reduced = reduced
+	s.particles = s.particles[:min(len(s.particles), s.maxParticles())]
+	s.numbers = s.numbers[:min(len(s.numbers), s.maxNumbers())]
+}
+
+// Reduced は演出を減らしているかを返します
+func (s *System) Reduced() bool {
+	return s.reduced
+}
+
+func (s *System) maxParticles() int {
+	if s.reduced {
+		return maxParticlesReduced
+	}
+	return maxParticles
+}
+
+func (s *System) maxNumbers() int {
+	if s.reduced {
+		return maxNumbersReduced
+	}
+	return maxNumbers
+}
+
+// Spawn はパーティクルを1つ出します。上限に達していれば出しません
+func (s *System) Spawn(p Particle) {
+	if len(s.particles) >= s.maxParticles() || p.Life <= 0 {
+		return
+	}
+	p.MaxLife = p.Life
+	s.particles = append(s.particles, p)
+}
+
+// Burst は (x, y) から n 個のパーティクルを四方に飛ばします
+// speed は最も速いパーティクルの1ティックあたりの速さです
+func (s *System) Burst(x, y float64, clr world.Color, n int, speed float64) {
+	if s.reduced {
+		n = max(n/reducedRatio, 1)
+	}
+	for range n {
+		angle := s.rng.Float64() * 2 * math.Pi
+		v := speed * (0.3 + 0.7*s.rng.Float64())
+		s.Spawn(Particle{
+			X: x, Y: y,
+			VX: math.Cos(angle) * v, VY: math.Sin(angle) * v,
+			Size:  2 + 2*s.rng.Float64(),
+			Color: clr,
+			Life:  20 + s.rng.IntN(20),
+		})
+	}
+}
+
+// Spark は武器が当たった位置に小さな火花を出します
+func (s *System) Spark(x, y float64) {
+	n := 4
+	if s.reduced {
+		n = 1
+	}
+	for range n {
+		angle := s.rng.Float64() * 2 * math.Pi
+		s.Spawn(Particle{
+			X: x, Y: y,
+			VX: math.Cos(angle) * 3, VY: math.Sin(angle) * 3,
+			Size:  2,
+			Color: world.Color{R: 255, G: 240, B: 160, A: 255},
+			Life:  8,
+		})
+	}
+}
+
+// Shimmer は (x, y) を中心とする半径 radius の円周上に、ゆっくり浮かぶ光を1つ出します
+// 毎ティック出し続ける演出なので、演出を減らしているときは出しません
+func (s *System) Shimmer(x, y, radius float64, clr world.Color) {
+	if s.reduced {
+		return
+	}
+	angle := s.rng.Float64() * 2 * math.Pi
+	s.Spawn(Particle{
+		X: x + math.Cos(angle)*radius, Y: y + math.Sin(angle)*radius,
+		VY:    -0.5,
+		Size:  3,
+		Color: clr,
+		Life:  30,
+	})
+}
+
+// Trail は弾の通った跡に止まったパーティクルを残します
+// 毎ティック出し続ける演出なので、演出を減らしているときは出しません
+func (s *System) Trail(x, y float64, clr world.Color) {
+	if s.reduced {
+		return
+	}
+	s.Spawn(Particle{X: x, Y: y, Size: 3, Color: clr, Life: 12})
+}
+
+// Number は (x, y) からダメージの数字を浮かび上がらせます。上限に達していれば出しません
+func (s *System) Number(x, y float64, value int, clr world.Color) {
+	if len(s.numbers) >= s.maxNumbers() {
+		return
+	}
+	const life = 40
+	// 同じ位置に重ならないよう少しずらす
+	x += (s.rng.Float64() - 0.5) * 16
+	s.numbers = append(s.numbers, Number{X: x, Y: y, Value: value, Color: clr, Life: life, MaxLife: life})
+}
+
+// Shake は画面を amount ピクセルの強さで揺らします。揺れている間は強い方を使います
+func (s *System) Shake(amount float64) {
+	s.shake = max(s.shake, amount)
+}
+
+// Offset は画面の揺れによるずれを返します。カメラの位置に足して使います
+func (s *System) Offset() (x, y float64) {
+	if s.shake < 0.5 {
+		return 0, 0
+	}
+	angle := s.rng.Float64() * 2 * math.Pi
+	return math.Cos(angle) * s.shake, math.Sin(angle) * s.shake
+}
+
+// Update は演出を1ティック進め、寿命の尽きたものを取り除きます
+func (s *System) Update() {
+	alive := s.particles[:0]
+	for _, p := range s.particles {
+		p.Life--
+		if p.Life <= 0 {
+			continue
+		}
+		p.X += p.VX
+		p.Y += p.VY
+		p.VX *= drag
+		p.VY *= drag
+		alive = append(alive, p)
+	}
+	s.particles = alive
+
+	numbers := s.numbers[:0]
+	for _, n := range s.numbers {
+		n.Life--
+		if n.Life <= 0 {
+			continue
+		}
+		n.Y -= 0.6
+		numbers = append(numbers, n)
+	}
+	s.numbers = numbers
+
+	s.shake *= shakeDecay
+}
+
+// Clear はすべての演出を消します
+func (s *System) Clear() {
+	s.particles = s.particles[:0]
+	s.numbers = s.numbers[:0]
+	s.shake = 0
+}
+
+// Particles は生きているパーティクルを返します
+// 返したスライスは次の Update で書き換わります
+func (s *System) Particles() []Particle {
+	return s.particles
+}
+
+// Numbers は表示中のダメージの数字を返します
+// 返したスライスは次の Update で書き換わります
+func (s *System) Numbers() []Number {
+	return s.numbers
+}
@@ -0,0 +1,80 @@
+package fx
+
+import (
+	"testing"
+
+	"vampire-survivors-like/world"
+)
+
+var white = world.Color{R: 255, G: 255, B: 255, A: 255}
+
+func TestParticlesExpire(t *testing.T) {
+	s := New()
+	s.Burst(0, 0, white, 10, 4)
+	if n := len(s.Particles()); n != 10 {
+		t.Fatalf("len(Particles()) = %d after a burst of 10, want 10", n)
+	}
+	s.Number(0, 0, 12, white)
+
+	for range 100 {
+		s.Update()
+	}
+	if n := len(s.Particles()); n != 0 {
+		t.Errorf("%d particles left after their lifetime", n)
+	}
+	if n := len(s.Numbers()); n != 0 {
+		t.Errorf("%d damage numbers left after their lifetime", n)
+	}
+}
+
+func TestPoolDoesNotGrow(t *testing.T) {
+	s := New()
+	capacity := cap(s.particles)
+	for range 100 {
+		s.Burst(0, 0, white, 100, 4)
+		s.Update()
+	}
+	if n := len(s.Particles()); n > maxParticles {
+		t.Errorf("len(Particles()) = %d, want at most %d", n, maxParticles)
+	}
+	if cap(s.particles) != capacity {
+		t.Errorf("particle pool grew from %d to %d", capacity, cap(s.particles))
+	}
+}
+
+func TestReduced(t *testing.T) {
+	s := New()
+	for range 10 {
+		s.Burst(0, 0, white, 100, 4)
+	}
+	s.SetReduced(true)
+	if n := len(s.Particles()); n > maxParticlesReduced {
+		t.Fatalf("len(Particles()) = %d after reducing, want at most %d", n, maxParticlesReduced)
+	}
+
+	// 減らしているときはバーストの数が減り、出し続ける演出は出さない
+	s.Clear()
+	s.Burst(0, 0, white, 20, 4)
+	s.Shimmer(0, 0, 50, white)
+	s.Trail(0, 0, white)
+	if n := len(s.Particles()); n != 20/reducedRatio {
+		t.Errorf("len(Particles()) = %d, want %d", n, 20/reducedRatio)
+	}
+}
+
+func TestShakeDecays(t *testing.T) {
+	s := New()
+	if x, y := s.Offset(); x != 0 || y != 0 {
+		t.Fatalf("Offset() = %v, %v before shaking, want 0, 0", x, y)
+	}
+	s.Shake(8)
+	if x, y := s.Offset(); x*x+y*y < 63 {
+		t.Errorf("Offset() = %v, %v, want a shake of 8", x, y)
+	}
+	for range 60 {
+		s.Update()
+	}
+	if x, y := s.Offset(); x != 0 || y != 0 {
+		t.Errorf("Offset() = %v, %v after a second, want 0, 0", x, y)
+	}
+}
@@ -0,0 +1,191 @@
+// Package i18n は画面に表示する文章を言語ごとのメッセージカタログから引きます
+//
+// 文章は ID で引き、言語ごとの JSON (ja.json, en.json) に書きます
+// 値は fmt の書式で、数によって形が変わる文章は {"one": ..., "other": ...} と書きます
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Lang は言語です
+type Lang string
+
+const (
+	Japanese Lang = "ja"
+	English  Lang = "en"
+)
+
+// Langs は対応している言語の一覧です。最初の言語が既定で、訳がないときの代わりにも使います
+var Langs = []Lang{Japanese, English}
+
+// pluralOne は言語ごとに、数 n のときに単数形を使うかを返します
+// 日本語のように数で形が変わらない言語は持ちません
+var pluralOne = map[Lang]func(n int) bool{
+	English: func(n int) bool { return n == 1 },
+}
+
+//go:embed ja.json en.json
+var bundleFiles embed.FS
+
+// Message は1つの文章です。数によって形が変わらなければ Other だけを使います
+type Message struct {
+	One   string `json:"one"`
+	Other string `json:"other"`
+}
+
+// UnmarshalJSON は文字列か {"one": ..., "other": ...} を読み込みます
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*m = Message{Other: s}
+		return nil
+	}
+	type plain Message
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode((*plain)(m)); err != nil {
+		return fmt.Errorf("message must be a string or {\"one\", \"other\"}: %w", err)
+	}
+	if m.Other == "" {
+		return fmt.Errorf("plural message has no \"other\" form")
+	}
+	return nil
+}
+
+// Bundle は1つの言語の ID ごとの文章です
+type Bundle map[string]Message
+
+var defaultBundles = mustLoadBundles()
+
+func mustLoadBundles() map[Lang]Bundle {
+	bundles := make(map[Lang]Bundle, len(Langs))
+	for _, lang := range Langs {
+		name := string(lang) + ".json"
+		data, err := bundleFiles.ReadFile(name)
+		if err != nil {
+			panic(fmt.Sprintf("embedded %s: %v", name, err))
+		}
+		var b Bundle
+		if err := json.Unmarshal(data, &b); err != nil {
+			panic(fmt.Sprintf("embedded %s: %v", name, err))
+		}
+		bundles[lang] = b
+	}
+	return bundles
+}
+
+// Catalog は現在の言語で文章を引きます
+type Catalog struct {
+	bundles map[Lang]Bundle
+	lang    Lang
+}
+
+// NewCatalog は組み込みの文章を lang で引くカタログを作成します
+func NewCatalog(lang Lang) (*Catalog, error) {
+	c := &Catalog{bundles: defaultBundles, lang: Langs[0]}
+	if err := c.SetLang(lang); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Lang は現在の言語を返します
+func (c *Catalog) Lang() Lang {
+	return c.lang
+}
+
+// SetLang は言語を切り替えます
+func (c *Catalog) SetLang(lang Lang) error {
+	if !slices.Contains(Langs, lang) {
+		return fmt.Errorf("unknown language %q", lang)
+	}
+	c.lang = lang
+	return nil
+}
+
+// NextLang は言語を一覧の次の言語に切り替えます。delta が負なら前の言語にします
+func (c *Catalog) NextLang(delta int) {
+	i := slices.Index(Langs, c.lang)
+	n := len(Langs)
+	c.lang = Langs[((i+delta)%n+n)%n]
+}
+
+// lookup は id の文章を返します。現在の言語に訳がなければ既定の言語の文章を返します
+func (c *Catalog) lookup(id string) (Message, bool) {
+	if m, ok := c.bundles[c.lang][id]; ok {
+		return m, true
+	}
+	m, ok := c.bundles[Langs[0]][id]
+	return m, ok
+}
+
+// T は id の文章を args で書式化して返します
+// どの言語にもない id はそのまま返すので、訳し忘れは画面で分かります
+func (c *Catalog) T(id string, args ...any) string {
+	m, ok := c.lookup(id)
+	if !ok {
+		return id
+	}
+	return format(m.Other, args)
+}
+
+// N は id の文章のうち n に合う形を args で書式化して返します
+func (c *Catalog) N(id string, n int, args ...any) string {
+	m, ok := c.lookup(id)
+	if !ok {
+		return id
+	}
+	s := m.Other
+	if one := pluralOne[c.lang]; m.One != "" && one != nil && one(n) {
+		s = m.One
+	}
+	return format(s, args)
+}
+
+// Or は id の文章を返します。id がカタログになければ fallback を返します
+// 定義ファイルで追加された武器などの名前は、訳がなければ定義ファイルの名前を使います
+func (c *Catalog) Or(id, fallback string) string {
+	if m, ok := c.lookup(id); ok {
+		return m.Other
+	}
+	return fallback
+}
+
+// Has は id の文章がいずれかの言語にあるかを返します
+func (c *Catalog) Has(id string) bool {
+	_, ok := c.lookup(id)
+	return ok
+}
+
+// Missing は言語ごとに、ほかの言語にはあってその言語にない ID を返します
+func (c *Catalog) Missing() map[Lang][]string {
+	all := make(map[string]bool)
+	for _, b := range c.bundles {
+		for id := range b {
+			all[id] = true
+		}
+	}
+	missing := make(map[Lang][]string)
+	for _, lang := range Langs {
+		for id := range all {
+			if _, ok := c.bundles[lang][id]; !ok {
+				missing[lang] = append(missing[lang], id)
+			}
+		}
+		sort.Strings(missing[lang])
+	}
+	return missing
+}
+
+func format(s string, args []any) string {
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
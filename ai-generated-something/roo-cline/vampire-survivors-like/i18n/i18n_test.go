@@ -0,0 +1,242 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"vampire-survivors-like/world"
+)
+
+// newTestCatalog は lang のカタログを作成します
+func newTestCatalog(t *testing.T, lang Lang) *Catalog {
+	t.Helper()
+	c, err := NewCatalog(lang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestBundlesHaveSameKeys(t *testing.T) {
+	c := newTestCatalog(t, Japanese)
+	for lang, ids := range c.Missing() {
+		for _, id := range ids {
+			t.Errorf("%s.json: missing %q", lang, id)
+		}
+	}
+}
+
+// TestIDsAreTranslated は ids.go の定数の ID がすべての言語にあることを確かめます
+func TestIDsAreTranslated(t *testing.T) {
+	requireAll(t, constIDs(t))
+}
+
+// constIDs は ids.go の定数の ID を返します
+func constIDs(t *testing.T) []string {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "ids.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, v := range spec.(*ast.ValueSpec).Values {
+				if lit, ok := v.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					id, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						t.Fatal(err)
+					}
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	if len(ids) == 0 {
+		t.Fatal("no message IDs found in ids.go")
+	}
+	return ids
+}
+
+// TestVerbsMatch は T や N で書式化する文章の書式の指定が、言語や数の形で違わないことを確かめます
+// 違うと、訳によっては画面に %!d(MISSING) などが出てしまいます
+// 定義の文言のように書式化しない文章は、% をそのまま書けるので確かめません
+func TestVerbsMatch(t *testing.T) {
+	for _, id := range constIDs(t) {
+		want := verbs(defaultBundles[Langs[0]][id].Other)
+		for _, lang := range Langs {
+			m, ok := defaultBundles[lang][id]
+			if !ok {
+				continue
+			}
+			forms := map[string]string{"other": m.Other}
+			if m.One != "" {
+				forms["one"] = m.One
+			}
+			for form, s := range forms {
+				if got := verbs(s); !slices.Equal(got, want) {
+					t.Errorf("%s.json: %q (%s) has verbs %q, want %q as in %s.json", lang, id, form, got, want, Langs[0])
+				}
+			}
+		}
+	}
+}
+
+func TestVerbs(t *testing.T) {
+	tests := map[string][]string{
+		"Level: %d  Score: %d": {"d", "d"},
+		"%02d:%02d  %.1f%%":    {"d", "d", "f"},
+		"%[2]s に %[1]d":        {"[2]s", "[1]d"},
+		"%*d":                  {"*", "d"},
+		"100%":                 nil,
+	}
+	for s, want := range tests {
+		if got := verbs(s); !slices.Equal(got, want) {
+			t.Errorf("verbs(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+// verbs は fmt の書式 s の引数を使う指定を順に返します
+// 幅や精度の違い（%d と %02d など）は区別せず、引数の番号と動詞だけを返します
+func verbs(s string) []string {
+	var vs []string
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		arg := "" // [n] で指定した引数の番号
+	spec:
+		for i++; i < len(s); i++ {
+			switch c := s[i]; {
+			case strings.IndexByte("+-# 0123456789.", c) >= 0:
+				// フラグ・幅・精度
+			case c == '[':
+				end := strings.IndexByte(s[i:], ']')
+				if end < 0 {
+					break spec
+				}
+				arg = s[i : i+end+1]
+				i += end
+			case c == '*':
+				vs = append(vs, arg+"*")
+				arg = ""
+			case c == '%':
+				break spec
+			default:
+				r, size := utf8.DecodeRuneInString(s[i:])
+				vs = append(vs, arg+string(r))
+				i += size - 1
+				break spec
+			}
+		}
+	}
+	return vs
+}
+
+// TestDefinitionsAreTranslated は組み込みの定義の文言がすべての言語にあることを確かめます
+func TestDefinitionsAreTranslated(t *testing.T) {
+	defs := world.DefaultDefinitions()
+	var ids []string
+	for _, w := range defs.Weapons {
+		ids = append(ids, WeaponName(w.WeaponType))
+		for level := 2; level <= w.MaxLevel(); level++ {
+			ids = append(ids, WeaponLevel(w.WeaponType, level))
+		}
+	}
+	for _, p := range defs.Passives {
+		ids = append(ids, PassiveName(p.PassiveType), PassiveDescription(p.PassiveType))
+	}
+	for _, u := range defs.Upgrades {
+		ids = append(ids, UpgradeName(u.UpgradeType), UpgradeDescription(u.UpgradeType))
+	}
+	for _, c := range defs.Characters {
+		ids = append(ids, CharacterName(c.CharacterType), CharacterDescription(c.CharacterType),
+			CharacterPassiveName(c.CharacterType), CharacterPassiveDescription(c.CharacterType))
+	}
+	for _, e := range defs.Enemies {
+		ids = append(ids, EnemyName(e.Type))
+	}
+	for _, s := range defs.Skills {
+		ids = append(ids, Skill(s.Type))
+	}
+	for _, st := range defs.Stages {
+		for _, ev := range st.Events {
+			if ev.Message != "" {
+				ids = append(ids, ev.Message)
+			}
+		}
+	}
+	requireAll(t, ids)
+}
+
+// requireAll は ids がすべての言語にあることを確かめます
+func requireAll(t *testing.T, ids []string) {
+	t.Helper()
+	for _, lang := range Langs {
+		b := defaultBundles[lang]
+		for _, id := range ids {
+			if _, ok := b[id]; !ok {
+				t.Errorf("%s.json: missing %q", lang, id)
+			}
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	en := newTestCatalog(t, English)
+	if got, want := en.N(LevelUpRemaining, 1, 1), " (1 more pick)"; got != want {
+		t.Errorf("en N(1) = %q, want %q", got, want)
+	}
+	if got, want := en.N(LevelUpRemaining, 2, 2), " (2 more picks)"; got != want {
+		t.Errorf("en N(2) = %q, want %q", got, want)
+	}
+	ja := newTestCatalog(t, Japanese)
+	if got, want := ja.N(LevelUpRemaining, 1, 1), " 残り1回"; got != want {
+		t.Errorf("ja N(1) = %q, want %q", got, want)
+	}
+}
+
+func TestLanguageSwitch(t *testing.T) {
+	c := newTestCatalog(t, Japanese)
+	if got := c.T(HUDLevelScore, 3, 120); got != "レベル: 3  スコア: 120" {
+		t.Errorf("ja T = %q", got)
+	}
+	c.NextLang(1)
+	if c.Lang() != English {
+		t.Fatalf("Lang() = %q after NextLang(1), want %q", c.Lang(), English)
+	}
+	if got := c.T(HUDLevelScore, 3, 120); got != "Level: 3  Score: 120" {
+		t.Errorf("en T = %q", got)
+	}
+	c.NextLang(1)
+	if c.Lang() != Japanese {
+		t.Errorf("Lang() = %q after wrapping around, want %q", c.Lang(), Japanese)
+	}
+	if err := c.SetLang("fr"); err == nil {
+		t.Error("SetLang(\"fr\") succeeded, want error")
+	}
+}
+
+func TestFallback(t *testing.T) {
+	c := newTestCatalog(t, English)
+	if got := c.T("no.such.id"); got != "no.such.id" {
+		t.Errorf("T(unknown) = %q, want the ID", got)
+	}
+	if got := c.Or(WeaponName("custom"), "カスタム"); got != "カスタム" {
+		t.Errorf("Or(unknown) = %q, want the fallback", got)
+	}
+	if got := c.Or("ボスが来る！", "ボスが来る！"); got != "ボスが来る！" {
+		t.Errorf("Or(plain text) = %q", got)
+	}
+}
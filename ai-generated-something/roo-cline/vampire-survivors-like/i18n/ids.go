@@ -0,0 +1,172 @@
+package i18n
+
+import (
+	"fmt"
+
+	"vampire-survivors-like/world"
+)
+
+// 画面に表示する文章の ID
+const (
+	LangName = "lang.name" // その言語での言語の名前
+
+	HUDLevelScore = "hud.level_score"
+	HUDTime       = "hud.time"
+	HUDPassive    = "hud.passive"
+	HUDPause      = "hud.pause"
+
+	TitleName    = "title.name"
+	TitleRecords = "title.records"
+	PausedTitle  = "paused.title"
+	ReplayEnded  = "replay.ended"
+	Coins        = "coins"
+
+	MenuStart    = "menu.start"
+	MenuResume   = "menu.resume"
+	MenuOptions  = "menu.options"
+	MenuUpgrades = "menu.upgrades"
+	MenuHint     = "menu.hint"
+
+	LevelUpTitle     = "levelup.title"
+	LevelUpRemaining = "levelup.remaining"
+	LevelUpActions   = "levelup.actions"
+	SkillOption      = "skill.option"
+	SkillDetail      = "skill.detail"
+
+	SkillNewWeaponDetail = "skill.new_weapon_detail"
+
+	ResultsTitle          = "results.title"
+	ResultsTime           = "results.time"
+	ResultsLevel          = "results.level"
+	ResultsScore          = "results.score"
+	ResultsKills          = "results.kills"
+	ResultsKillsByEnemy   = "results.kills_by_enemy"
+	ResultsDamageByWeapon = "results.damage_by_weapon"
+	ResultsValue          = "results.value"
+	ResultsHint           = "results.hint"
+	ResultsReplayHint     = "results.replay_hint"
+	ReplayEndedHint       = "replay.ended.hint"
+	ResultsCoins          = "results.coins"
+	ResultsNewRecord      = "results.new_record"
+	ResultsUnlocked       = "results.unlocked"
+
+	OptionsTitle    = "options.title"
+	OptionsLanguage = "options.language"
+	OptionsControls = "options.controls"
+	OptionsMusic    = "options.music"
+	OptionsSFX      = "options.sfx"
+	OptionsEffects  = "options.effects"
+	OptionsDefs     = "options.defs"
+
+	OptionsEffectsFull    = "options.effects.full"
+	OptionsEffectsReduced = "options.effects.reduced"
+	OptionsHint           = "options.hint"
+	OptionsDefsReloaded   = "options.defs.reloaded"
+	OptionsDefsFailed     = "options.defs.failed"
+
+	ControlsTitle   = "controls.title"
+	ControlsWaiting = "controls.waiting"
+	ControlsReset   = "controls.reset"
+	ControlsHint    = "controls.hint"
+	ControlsOnlyKey = "controls.only_key"
+
+	UpgradesTitle     = "upgrades.title"
+	UpgradesLevel     = "upgrades.level"
+	UpgradesPrice     = "upgrades.price"
+	UpgradesMax       = "upgrades.max"
+	UpgradesNotEnough = "upgrades.not_enough"
+	UpgradesHint      = "upgrades.hint"
+
+	CharactersTitle   = "characters.title"
+	CharactersWeapon  = "characters.weapon"
+	CharactersStats   = "characters.stats"
+	CharactersPassive = "characters.passive"
+	CharactersLocked  = "characters.locked"
+	CharactersHint    = "characters.hint"
+
+	// キャラクターを選べるようになる条件
+	UnlockSurvival   = "unlock.survival"
+	UnlockScore      = "unlock.score"
+	UnlockLevel      = "unlock.level"
+	UnlockKills      = "unlock.kills"
+	UnlockKillsTotal = "unlock.kills_total"
+	UnlockRuns       = "unlock.runs"
+
+	// 操作の名前
+	ActionUp      = "action.up"
+	ActionDown    = "action.down"
+	ActionLeft    = "action.left"
+	ActionRight   = "action.right"
+	ActionConfirm = "action.confirm"
+	ActionCancel  = "action.cancel"
+	ActionPause   = "action.pause"
+	ActionChoice1 = "action.choice1"
+	ActionChoice2 = "action.choice2"
+	ActionChoice3 = "action.choice3"
+	ActionReroll  = "action.reroll"
+	ActionSkip    = "action.skip"
+	ActionBanish  = "action.banish"
+)
+
+// 定義ファイルの項目の文章の ID
+// 訳がなければ定義ファイルの name や description を使います
+
+// WeaponName は武器の名前の ID です
+func WeaponName(id world.WeaponType) string {
+	return fmt.Sprintf("weapon.%s.name", id)
+}
+
+// WeaponLevel は武器がレベル level に上がったときの強化内容の ID です
+func WeaponLevel(id world.WeaponType, level int) string {
+	return fmt.Sprintf("weapon.%s.lv%d", id, level)
+}
+
+// PassiveName はパッシブアイテムの名前の ID です
+func PassiveName(id world.PassiveType) string {
+	return fmt.Sprintf("passive.%s.name", id)
+}
+
+// PassiveDescription はパッシブアイテムの効果の ID です
+func PassiveDescription(id world.PassiveType) string {
+	return fmt.Sprintf("passive.%s.description", id)
+}
+
+// UpgradeName は永続的な強化の名前の ID です
+func UpgradeName(id world.UpgradeType) string {
+	return fmt.Sprintf("upgrade.%s.name", id)
+}
+
+// UpgradeDescription は永続的な強化の効果の ID です
+func UpgradeDescription(id world.UpgradeType) string {
+	return fmt.Sprintf("upgrade.%s.description", id)
+}
+
+// CharacterName はキャラクターの名前の ID です
+func CharacterName(id world.CharacterType) string {
+	return fmt.Sprintf("character.%s.name", id)
+}
+
+// CharacterDescription はキャラクターの説明の ID です
+func CharacterDescription(id world.CharacterType) string {
+	return fmt.Sprintf("character.%s.description", id)
+}
+
+// CharacterPassiveName はキャラクターだけが持つパッシブの名前の ID です
+func CharacterPassiveName(id world.CharacterType) string {
+	return fmt.Sprintf("character.%s.passive.name", id)
+}
+
+// CharacterPassiveDescription はキャラクターだけが持つパッシブの効果の ID です
+func CharacterPassiveDescription(id world.CharacterType) string {
+	return fmt.Sprintf("character.%s.passive.description", id)
+}
+
+// EnemyName は敵の名前の ID です
+func EnemyName(id world.EnemyType) string {
+	return fmt.Sprintf("enemy.%s.name", id)
+}
+
+// Skill はスキルの種類の ID です
+func Skill(t world.SkillType) string {
+	return fmt.Sprintf("skill.%s", t)
+}
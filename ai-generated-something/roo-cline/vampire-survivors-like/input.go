@@ -0,0 +1,266 @@
+package main
+
+import (
+	"log"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/world"
+)
+
+// Action はキーやボタンに割り当てる操作です
+type Action int
+
+const (
+	ActionUp Action = iota
+	ActionDown
+	ActionLeft
+	ActionRight
+	ActionConfirm // 決定・リスタート
+	ActionCancel  // 戻る
+	ActionPause
+	ActionChoice1 // スキルの選択肢を番号で選ぶ
+	ActionChoice2
+	ActionChoice3
+	ActionReroll // スキルの選択肢を引き直す
+	ActionSkip   // スキルを選ばずに飛ばす
+	ActionBanish // 選んでいるスキルの選択肢を除外する
+	actionCount
+)
+
+// actionNames は設定ファイルに書く操作の名前です
+var actionNames = [actionCount]string{
+	"up", "down", "left", "right", "confirm", "cancel", "pause", "choice1", "choice2", "choice3",
+	"reroll", "skip", "banish",
+}
+
+// actionLabels は画面に表示する操作の名前の ID です
+var actionLabels = [actionCount]string{
+	i18n.ActionUp, i18n.ActionDown, i18n.ActionLeft, i18n.ActionRight,
+	i18n.ActionConfirm, i18n.ActionCancel, i18n.ActionPause,
+	i18n.ActionChoice1, i18n.ActionChoice2, i18n.ActionChoice3,
+	i18n.ActionReroll, i18n.ActionSkip, i18n.ActionBanish,
+}
+
+// defaultKeys は初期設定のキー割り当てです
+var defaultKeys = [actionCount][]ebiten.Key{
+	ActionUp:      {ebiten.KeyW, ebiten.KeyArrowUp},
+	ActionDown:    {ebiten.KeyS, ebiten.KeyArrowDown},
+	ActionLeft:    {ebiten.KeyA, ebiten.KeyArrowLeft},
+	ActionRight:   {ebiten.KeyD, ebiten.KeyArrowRight},
+	ActionConfirm: {ebiten.KeyEnter, ebiten.KeySpace},
+	ActionCancel:  {ebiten.KeyEscape, ebiten.KeyBackspace},
+	ActionPause:   {ebiten.KeyEscape, ebiten.KeyP},
+	ActionChoice1: {ebiten.Key1},
+	ActionChoice2: {ebiten.Key2},
+	ActionChoice3: {ebiten.Key3},
+	ActionReroll:  {ebiten.KeyR},
+	ActionSkip:    {ebiten.KeyK},
+	ActionBanish:  {ebiten.KeyB},
+}
+
+// padButtons はゲームパッド（標準配置）のボタンの割り当てです。変更はできません
+var padButtons = [actionCount][]ebiten.StandardGamepadButton{
+	ActionUp:      {ebiten.StandardGamepadButtonLeftTop},
+	ActionDown:    {ebiten.StandardGamepadButtonLeftBottom},
+	ActionLeft:    {ebiten.StandardGamepadButtonLeftLeft},
+	ActionRight:   {ebiten.StandardGamepadButtonLeftRight},
+	ActionConfirm: {ebiten.StandardGamepadButtonRightBottom},
+	ActionCancel:  {ebiten.StandardGamepadButtonRightRight},
+	ActionPause:   {ebiten.StandardGamepadButtonCenterRight},
+	ActionReroll:  {ebiten.StandardGamepadButtonRightTop},
+	ActionSkip:    {ebiten.StandardGamepadButtonRightLeft},
+	ActionBanish:  {ebiten.StandardGamepadButtonFrontTopRight},
+}
+
+// スティックをこれより小さく倒したときは倒していないものとする
+const stickDeadZone = 0.2
+
+// Controls はキーボードとゲームパッドの状態を操作に変換します
+type Controls struct {
+	keys [actionCount][]ebiten.Key
+	pads []ebiten.GamepadID // 接続されているゲームパッド
+}
+
+// NewControls は初期設定に設定ファイルのキー割り当て keys を重ねた Controls を作成します
+// 知らない操作やキーの名前は無視します。キーが1つもない操作は初期設定のままにします
+func NewControls(keys map[string][]string) *Controls {
+	c := &Controls{}
+	c.Reset()
+	for a, name := range actionNames {
+		names, ok := keys[name]
+		if !ok {
+			continue
+		}
+		var bound []ebiten.Key
+		for _, n := range names {
+			var k ebiten.Key
+			if err := k.UnmarshalText([]byte(n)); err != nil {
+				log.Printf("config: action %q: %v", name, err)
+				continue
+			}
+			bound = append(bound, k)
+		}
+		if len(bound) == 0 {
+			continue
+		}
+		c.keys[a] = bound
+	}
+	return c
+}
+
+// Reset はキー割り当てを初期設定に戻します
+func (c *Controls) Reset() {
+	for a, keys := range defaultKeys {
+		c.keys[a] = append([]ebiten.Key(nil), keys...)
+	}
+}
+
+// Config は設定ファイルに保存するキー割り当てを返します
+func (c *Controls) Config() map[string][]string {
+	m := make(map[string][]string, actionCount)
+	for a, keys := range c.keys {
+		names := make([]string, 0, len(keys))
+		for _, k := range keys {
+			names = append(names, k.String())
+		}
+		m[actionNames[a]] = names
+	}
+	return m
+}
+
+// KeyName は操作 a に割り当てた最初のキーの名前を返します。割り当てがなければ "-" を返します
+func (c *Controls) KeyName(a Action) string {
+	if len(c.keys[a]) == 0 {
+		return "-"
+	}
+	return c.keys[a][0].String()
+}
+
+// Keys は操作 a に割り当てたキーを返します
+func (c *Controls) Keys(a Action) []ebiten.Key {
+	return c.keys[a]
+}
+
+// SetKey は操作 a を key だけに割り当て、true を返します
+// ほかの操作に同じキーが割り当てられていれば、そちらからは外します
+// ただし外すとキーが1つもなくなる操作があれば、割り当てを変えずにその操作と false を返します
+// キーボードだけでは決定や戻るができなくなり、初期設定にも戻せなくなるのを防ぐためです
+func (c *Controls) SetKey(a Action, key ebiten.Key) (Action, bool) {
+	for b, keys := range c.keys {
+		if Action(b) != a && len(keys) == 1 && keys[0] == key {
+			return Action(b), false
+		}
+	}
+	for b := range c.keys {
+		c.keys[b] = slices.DeleteFunc(c.keys[b], func(k ebiten.Key) bool { return k == key })
+	}
+	c.keys[a] = []ebiten.Key{key}
+	return a, true
+}
+
+// update は接続されているゲームパッドを調べます。毎ティックの最初に呼びます
+func (c *Controls) update() {
+	c.pads = ebiten.AppendGamepadIDs(c.pads[:0])
+}
+
+// Pressed は操作 a のキーかボタンが押されているかを返します
+func (c *Controls) Pressed(a Action) bool {
+	for _, k := range c.keys[a] {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	for _, id := range c.pads {
+		for _, b := range padButtons[a] {
+			if ebiten.IsStandardGamepadButtonPressed(id, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// JustPressed は操作 a のキーかボタンがこのティックで押されたかを返します
+// 押し続けても最初の1ティックだけ true になります
+func (c *Controls) JustPressed(a Action) bool {
+	for _, k := range c.keys[a] {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	for _, id := range c.pads {
+		for _, b := range padButtons[a] {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Move は移動の方向を返します
+// スティックを倒していればその傾き、そうでなければ方向キーの向きです。長さは1以下です
+func (c *Controls) Move() (x, y float64) {
+	for _, id := range c.pads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		sx := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		sy := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		if l := math.Hypot(sx, sy); l > stickDeadZone {
+			// 遊びの分を差し引いて、倒し始めから滑らかに速くなるようにする
+			scale := min(1, (l-stickDeadZone)/(1-stickDeadZone)) / l
+			return sx * scale, sy * scale
+		}
+	}
+	if c.Pressed(ActionLeft) {
+		x--
+	}
+	if c.Pressed(ActionRight) {
+		x++
+	}
+	if c.Pressed(ActionUp) {
+		y--
+	}
+	if c.Pressed(ActionDown) {
+		y++
+	}
+	if l := math.Hypot(x, y); l > 1 {
+		x /= l
+		y /= l
+	}
+	return x, y
+}
+
+// ControlsInput は Controls からゲームの入力を作成します
+type ControlsInput struct {
+	controls *Controls
+}
+
+// Next は現在のキーボードとゲームパッドの状態を返します
+// 移動は押している間ずっと、選択とリスタートは押した瞬間の1ティックだけ入力になります
+// （押し続けても次のスキル選択で同じ番号を選ばないように）
+func (i ControlsInput) Next() (world.Input, bool) {
+	in := world.Input{Restart: i.controls.JustPressed(ActionConfirm)}
+	in.MoveX, in.MoveY = i.controls.Move()
+	for n, a := range []Action{ActionChoice1, ActionChoice2, ActionChoice3} {
+		if i.controls.JustPressed(a) {
+			in.Choice = n + 1
+			break
+		}
+	}
+	switch {
+	case i.controls.JustPressed(ActionReroll):
+		in.SkillAction = world.SkillActionReroll
+	case i.controls.JustPressed(ActionSkip):
+		in.SkillAction = world.SkillActionSkip
+	case i.controls.JustPressed(ActionBanish):
+		in.SkillAction = world.SkillActionBanish
+	}
+	return in, true
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"vampire-survivors-like/ui"
+)
+
+// 選んでいる項目の色
+var selectedColor = color.RGBA{255, 220, 80, 255}
+
+// menu は上下で選んで決定する縦に並んだ項目です
+type menu struct {
+	cursor int
+}
+
+// update は上下の操作でカーソルを動かし、決定された項目の番号を返します
+// 決定されなければ -1 を返します
+func (m *menu) update(c *Controls, n int) int {
+	if n == 0 {
+		return -1
+	}
+	switch {
+	case c.JustPressed(ActionUp):
+		m.cursor = (m.cursor + n - 1) % n
+	case c.JustPressed(ActionDown):
+		m.cursor = (m.cursor + 1) % n
+	case c.JustPressed(ActionConfirm):
+		return min(m.cursor, n-1)
+	}
+	return -1
+}
+
+// draw は items を (x, y) から下に spacing ずつ離して中央揃えで描画し、選んでいる項目を強調します
+func (m *menu) draw(screen *ebiten.Image, items []string, x, y, spacing float64) {
+	for i, item := range items {
+		opts := ui.TextOptions{Outline: outlineColor}
+		if i == m.cursor {
+			opts.Color = selectedColor
+			item = "> " + item + " <"
+		}
+		ui.Centered(screen, item, x, y+float64(i)*spacing, opts)
+	}
+}
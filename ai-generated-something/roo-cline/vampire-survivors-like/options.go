@@ -0,0 +1,239 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/ui"
+	"vampire-survivors-like/world"
+)
+
+// オプション画面の項目
+const (
+	optionLanguage = iota
+	optionMusic
+	optionSFX
+	optionEffects
+	optionControls
+	optionCount
+
+	optionDefs = optionCount // -defs で起動したときだけ出す
+)
+
+// optionsScene はオプション画面です。閉じると back の scene に戻ります
+type optionsScene struct {
+	back    scene
+	menu    menu
+	message string // 定義の再読み込みの結果
+}
+
+func (*optionsScene) enter(*Game) {}
+
+// exit は変更した設定を保存します
+func (*optionsScene) exit(g *Game) {
+	g.saveConfig()
+}
+
+func (s *optionsScene) update(g *Game) {
+	if g.controls.JustPressed(ActionCancel) {
+		g.changeScene(s.back)
+		return
+	}
+	delta := 0
+	switch {
+	case g.controls.JustPressed(ActionLeft):
+		delta = -1
+	case g.controls.JustPressed(ActionRight):
+		delta = 1
+	}
+	if delta != 0 {
+		switch s.menu.cursor {
+		case optionLanguage:
+			g.text.NextLang(delta)
+		case optionMusic:
+			g.setMusicVolume(g.musicVolume + float64(delta)*volumeStep)
+		case optionSFX:
+			g.setSFXVolume(g.sfxVolume + float64(delta)*volumeStep)
+		case optionEffects:
+			g.fx.SetReduced(!g.fx.Reduced())
+		}
+	}
+	count := optionCount
+	if g.canReloadDefinitions() {
+		count++
+	}
+	switch s.menu.update(g.controls, count) {
+	case optionLanguage:
+		g.text.NextLang(1)
+	case optionMusic:
+		g.setMusicVolume(nextVolume(g.musicVolume))
+	case optionSFX:
+		g.setSFXVolume(nextVolume(g.sfxVolume))
+	case optionEffects:
+		g.fx.SetReduced(!g.fx.Reduced())
+	case optionControls:
+		g.changeScene(&controlsScene{back: s, waiting: -1})
+	case optionDefs:
+		s.message = g.text.T(i18n.OptionsDefsReloaded)
+		if err := g.reloadDefinitions(); err != nil {
+			log.Printf("failed to reload definitions: %v", err)
+			s.message = g.text.T(i18n.OptionsDefsFailed)
+		}
+	}
+}
+
+func (s *optionsScene) draw(g *Game, screen *ebiten.Image) {
+	s.back.draw(g, screen)
+	fillRect(screen, 0, 0, world.ScreenWidth, world.ScreenHeight, color.RGBA{0, 0, 0, 200})
+	cx, cy := float64(world.ScreenWidth)/2, float64(world.ScreenHeight)/2
+	ui.Centered(screen, g.text.T(i18n.OptionsTitle), cx, cy-110, ui.TextOptions{Scale: 2, Outline: outlineColor})
+	items := []string{
+		g.text.T(i18n.OptionsLanguage, g.text.T(i18n.LangName)),
+		g.text.T(i18n.OptionsMusic, int(math.Round(g.musicVolume*100))),
+		g.text.T(i18n.OptionsSFX, int(math.Round(g.sfxVolume*100))),
+		g.text.T(i18n.OptionsEffects, g.effectsName()),
+		g.text.T(i18n.OptionsControls),
+	}
+	if g.canReloadDefinitions() {
+		items = append(items, g.text.T(i18n.OptionsDefs))
+	}
+	s.menu.draw(screen, items, cx, cy-40, 30)
+	ui.Centered(screen, g.text.T(i18n.OptionsHint), cx, cy+140, ui.TextOptions{Color: hintColor})
+	if s.message != "" {
+		ui.Centered(screen, s.message, cx, cy+170, ui.TextOptions{Color: selectedColor})
+	}
+}
+
+// canReloadDefinitions は定義ファイルを読み直せるかを返します
+// 入力の記録中とリプレイの再生中は、記録した定義と変わらないよう読み直さない
+func (g *Game) canReloadDefinitions() bool {
+	return g.defsPath != "" && g.recorder == nil && g.live()
+}
+
+// reloadDefinitions は -defs の定義ファイルを読み直し、次のゲームから使います
+// 遊んでいるゲームの途中では定義を変えません
+func (g *Game) reloadDefinitions() error {
+	defs, err := world.LoadDefinitions(g.defsPath)
+	if err != nil {
+		return err
+	}
+	g.world.SetDefinitions(defs)
+	// 定義が変わって条件を満たしたキャラクターがあれば、選べるようにする
+	if len(g.records.Unlock(defs)) > 0 {
+		g.saveRecords()
+	}
+	return nil
+}
+
+// controlsScene はキー割り当ての変更画面です
+// 操作を選んで決定すると、次に押したキーがその操作に割り当てられます
+type controlsScene struct {
+	back    scene
+	menu    menu
+	waiting Action // キーを待っている操作（-1 なら待っていない）
+	message string // 割り当てられなかった理由
+}
+
+func (*controlsScene) enter(*Game) {}
+
+func (*controlsScene) exit(g *Game) {
+	g.saveConfig()
+}
+
+func (s *controlsScene) update(g *Game) {
+	if s.waiting >= 0 {
+		// Esc だけは割り当てに関係なく取り消しに使う
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			s.waiting = -1
+			return
+		}
+		if keys := inpututil.AppendJustPressedKeys(nil); len(keys) > 0 {
+			s.message = ""
+			if other, ok := g.controls.SetKey(s.waiting, keys[0]); !ok {
+				s.message = g.text.T(i18n.ControlsOnlyKey, keys[0].String(), g.text.T(actionLabels[other]))
+			}
+			s.waiting = -1
+		}
+		return
+	}
+	if g.controls.JustPressed(ActionCancel) {
+		g.changeScene(s.back)
+		return
+	}
+	// 最後の項目は初期設定に戻す
+	switch i := s.menu.update(g.controls, int(actionCount)+1); {
+	case i == int(actionCount):
+		g.controls.Reset()
+	case i >= 0:
+		s.waiting = Action(i)
+	}
+}
+
+func (s *controlsScene) draw(g *Game, screen *ebiten.Image) {
+	screen.Fill(color.RGBA{16, 20, 16, 255})
+	cx := float64(world.ScreenWidth) / 2
+	ui.Centered(screen, g.text.T(i18n.ControlsTitle), cx, 50, ui.TextOptions{Scale: 2, Outline: outlineColor})
+
+	const top, spacing = 100, 30
+	for a := range actionCount {
+		y := float64(top + int(a)*spacing)
+		opts := ui.TextOptions{}
+		if int(a) == s.menu.cursor {
+			opts.Color = selectedColor
+		}
+		label := opts
+		label.Align = ui.AlignEnd
+		ui.Text(screen, g.text.T(actionLabels[a]), cx-40, y, label)
+
+		keys := ""
+		if a == s.waiting {
+			keys = g.text.T(i18n.ControlsWaiting)
+		} else {
+			for i, k := range g.controls.Keys(a) {
+				if i > 0 {
+					keys += ", "
+				}
+				keys += k.String()
+			}
+		}
+		ui.Text(screen, keys, cx, y, opts)
+	}
+	reset := ui.TextOptions{Outline: outlineColor}
+	if s.menu.cursor == int(actionCount) {
+		reset.Color = selectedColor
+	}
+	ui.Centered(screen, g.text.T(i18n.ControlsReset), cx, float64(top+int(actionCount)*spacing+20), reset)
+
+	if s.message != "" {
+		ui.Centered(screen, s.message, cx, world.ScreenHeight-70, ui.TextOptions{Color: color.RGBA{255, 80, 80, 255}})
+	}
+	ui.Centered(screen, g.text.T(i18n.ControlsHint), cx, world.ScreenHeight-40, ui.TextOptions{Color: hintColor})
+}
+
+// effectsName は演出の量の設定の表示名を返します
+func (g *Game) effectsName() string {
+	if g.fx.Reduced() {
+		return g.text.T(i18n.OptionsEffectsReduced)
+	}
+	return g.text.T(i18n.OptionsEffectsFull)
+}
+
+// saveConfig は言語・音量・演出・キー割り当てを設定ファイルに保存します
+func (g *Game) saveConfig() {
+	if g.configPath == "" {
+		return
+	}
+	g.config.Lang = string(g.text.Lang())
+	g.config.Keys = g.controls.Config()
+	g.config.ReducedEffects = g.fx.Reduced()
+	music, sfx := g.musicVolume, g.sfxVolume
+	g.config.MusicVolume, g.config.SFXVolume = &music, &sfx
+	if err := g.config.Save(g.configPath); err != nil {
+		log.Printf("failed to save config: %v", err)
+	}
+}
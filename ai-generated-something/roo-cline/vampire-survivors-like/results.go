@@ -0,0 +1,142 @@
+package main
+
+import (
+	"cmp"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/save"
+	"vampire-survivors-like/ui"
+	"vampire-survivors-like/world"
+)
+
+// resultsScene はゲームオーバー後の結果画面です
+// 決定でのリスタートはゲームの入力なので、ここでもゲームを進めて記録します
+// リプレイの再生中は、戻るでタイトル画面に戻らずにゲームを終了します
+type resultsScene struct {
+	stats    world.Stats // 入ったときのプレイの統計
+	result   save.Result // 記録に足した結果
+	recorded bool        // 記録に足したか（リプレイの再生中は足さない）
+}
+
+func (s *resultsScene) enter(g *Game) {
+	s.stats = g.world.Stats()
+	g.sound.PlayMusic("")
+	if g.live() {
+		s.result = g.records.Record(g.world.Definitions(), s.stats)
+		s.recorded = true
+		g.saveRecords()
+	}
+}
+
+func (*resultsScene) exit(*Game) {}
+
+func (*resultsScene) update(g *Game) {
+	if g.controls.JustPressed(ActionCancel) {
+		if !g.live() {
+			g.quit = true
+			return
+		}
+		g.changeScene(&titleScene{})
+		return
+	}
+	if g.stepWorld() {
+		g.followWorld()
+	}
+}
+
+// statRow は結果画面の表の1行です
+type statRow struct {
+	name  string
+	value int
+}
+
+// sortedRows は値の大きい順に並べた表の行を返します
+func sortedRows[K ~string](m map[K]int, name func(K) string) []statRow {
+	rows := make([]statRow, 0, len(m))
+	for k, v := range m {
+		rows = append(rows, statRow{name: name(k), value: v})
+	}
+	slices.SortFunc(rows, func(a, b statRow) int {
+		return cmp.Or(cmp.Compare(b.value, a.value), cmp.Compare(a.name, b.name))
+	})
+	return rows
+}
+
+func (s *resultsScene) draw(g *Game, screen *ebiten.Image) {
+	g.drawWorld(screen)
+	fillRect(screen, 0, 0, world.ScreenWidth, world.ScreenHeight, color.RGBA{0, 0, 0, 200})
+
+	cx := float64(world.ScreenWidth) / 2
+	ui.Centered(screen, g.text.T(i18n.ResultsTitle), cx, 60, ui.TextOptions{Scale: 2, Outline: outlineColor})
+
+	seconds := int(s.stats.SurvivalTime)
+	totalKills := 0
+	for _, n := range s.stats.Kills {
+		totalKills += n
+	}
+	newRecord := func(line string, ok bool) string {
+		if ok {
+			line += " " + g.text.T(i18n.ResultsNewRecord)
+		}
+		return line
+	}
+	summary := []string{
+		newRecord(g.text.T(i18n.ResultsTime, seconds/60, seconds%60), s.result.LongestSurvival),
+		g.text.T(i18n.ResultsLevel, s.stats.Level),
+		newRecord(g.text.T(i18n.ResultsScore, s.stats.Score), s.result.BestScore),
+		g.text.T(i18n.ResultsKills, totalKills),
+	}
+	if s.recorded {
+		summary = append(summary, g.text.T(i18n.ResultsCoins, s.result.Coins, g.records.Coins))
+	}
+	for _, id := range s.result.Unlocked {
+		c, _ := g.world.Definitions().Character(id)
+		summary = append(summary, g.text.T(i18n.ResultsUnlocked, g.characterName(c)))
+	}
+	for i, line := range summary {
+		ui.Centered(screen, line, cx, float64(100+i*20), ui.TextOptions{})
+	}
+
+	// 敵の種類ごとの撃破数と、武器ごとの与ダメージを左右に並べる
+	defs := g.world.Definitions()
+	kills := sortedRows(s.stats.Kills, g.enemyName)
+	damage := sortedRows(s.stats.DamageDealt, func(id world.WeaponType) string {
+		w, _ := defs.Weapon(id)
+		return g.text.Or(i18n.WeaponName(id), cmp.Or(w.Name, string(id)))
+	})
+	columns := []struct {
+		x     float64
+		title string
+		rows  []statRow
+	}{
+		{cx - 200, g.text.T(i18n.ResultsKillsByEnemy), kills},
+		{cx + 40, g.text.T(i18n.ResultsDamageByWeapon), damage},
+	}
+	// 選べるようになったキャラクターが多ければ、表を下にずらす
+	const maxRows = 10
+	top := max(210, 110+len(summary)*20)
+	for _, col := range columns {
+		ui.Text(screen, col.title, col.x, float64(top), ui.TextOptions{Color: hintColor})
+		for i, row := range col.rows[:min(len(col.rows), maxRows)] {
+			y := float64(top + 24 + i*18)
+			ui.Text(screen, row.name, col.x, y, ui.TextOptions{})
+			ui.Text(screen, g.text.T(i18n.ResultsValue, row.value), col.x+160, y, ui.TextOptions{Align: ui.AlignEnd})
+		}
+	}
+
+	hint := g.text.T(i18n.ResultsHint)
+	if !g.live() {
+		hint = g.text.T(i18n.ResultsReplayHint)
+	}
+	ui.Centered(screen, hint, cx, world.ScreenHeight-50, ui.TextOptions{Color: hintColor})
+}
+
+// enemyName は敵の名前を現在の言語で返します
+// 定義ファイルの敵には名前がないので、訳がなければ id を使います
+func (g *Game) enemyName(id world.EnemyType) string {
+	return g.text.Or(i18n.EnemyName(id), string(id))
+}
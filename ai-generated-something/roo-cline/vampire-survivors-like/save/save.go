@@ -0,0 +1,213 @@
+// Package save はゲームをまたいで残る記録（最高記録・累計の撃破数・コイン・強化・キャラクター）を保存します
+//
+// 記録は JSON で保存し、形式を変えるときは Version を上げて古い形式からの変換を migrations に足します
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"vampire-survivors-like/world"
+)
+
+// Version は今の記録の形式のバージョンです
+const Version = 2
+
+// migration は1つ前のバージョンの記録を変換します
+type migration func(raw map[string]json.RawMessage) error
+
+// migrations[i] はバージョン i+1 の記録をバージョン i+2 に変換します
+var migrations = []migration{
+	// 2: 最高レベルと選べるようになったキャラクターを記録する
+	func(raw map[string]json.RawMessage) error {
+		raw["best_level"] = json.RawMessage("0")
+		raw["unlocked"] = json.RawMessage("[]")
+		return nil
+	},
+}
+
+// ErrNotEnoughCoins はコインが足りないときのエラーです
+var ErrNotEnoughCoins = errors.New("not enough coins")
+
+// ErrMaxLevel は強化が最大レベルに達しているときのエラーです
+var ErrMaxLevel = errors.New("upgrade is at max level")
+
+// Data はゲームをまたいで残る記録です
+type Data struct {
+	Version         int                       `json:"version"`
+	Runs            int                       `json:"runs"`             // 遊んだ回数
+	BestScore       int                       `json:"best_score"`       // 最高スコア
+	LongestSurvival float64                   `json:"longest_survival"` // 最長の生存時間（秒）
+	BestLevel       int                       `json:"best_level"`       // 最高レベル
+	Kills           map[world.EnemyType]int   `json:"kills"`            // 敵の種類ごとの累計の撃破数
+	Coins           int                       `json:"coins"`            // 使えるコイン
+	Upgrades        map[world.UpgradeType]int `json:"upgrades"`         // 買った強化のレベル
+	Unlocked        []world.CharacterType     `json:"unlocked"`         // 条件を満たして選べるようになったキャラクター
+	Character       world.CharacterType       `json:"character"`        // 最後に選んだキャラクター
+}
+
+// New は空の記録を作成します
+func New() *Data {
+	return &Data{
+		Version:  Version,
+		Kills:    make(map[world.EnemyType]int),
+		Upgrades: make(map[world.UpgradeType]int),
+		Unlocked: []world.CharacterType{},
+	}
+}
+
+// Decode は JSON から記録を読み込みます。古い形式の記録は今の形式に変換します
+func Decode(data []byte) (*Data, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if err := migrate(raw, migrations); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	d := New()
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	// 空の記録から書いた項目がないときも使えるようにする
+	if d.Kills == nil {
+		d.Kills = make(map[world.EnemyType]int)
+	}
+	if d.Upgrades == nil {
+		d.Upgrades = make(map[world.UpgradeType]int)
+	}
+	if d.Unlocked == nil {
+		d.Unlocked = []world.CharacterType{}
+	}
+	return d, nil
+}
+
+// migrate は raw のバージョンから順に migrations を適用し、最新のバージョンにします
+// 最新のバージョンは len(migrations)+1 です
+func migrate(raw map[string]json.RawMessage, migrations []migration) error {
+	var version int
+	if err := json.Unmarshal(raw["version"], &version); err != nil {
+		return fmt.Errorf("version: %w", err)
+	}
+	latest := len(migrations) + 1
+	if version < 1 || version > latest {
+		return fmt.Errorf("unsupported save version %d", version)
+	}
+	for v := version; v < latest; v++ {
+		if err := migrations[v-1](raw); err != nil {
+			return fmt.Errorf("migrate from version %d: %w", v, err)
+		}
+		raw["version"] = json.RawMessage(fmt.Sprint(v + 1))
+	}
+	return nil
+}
+
+// Encode は記録を JSON にします
+func (d *Data) Encode() ([]byte, error) {
+	d.Version = Version
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Result は1回のプレイを記録した結果です
+type Result struct {
+	Coins           int  // 得たコイン
+	BestScore       bool // 最高スコアを更新した
+	LongestSurvival bool // 最長の生存時間を更新した
+
+	Unlocked []world.CharacterType // このプレイで選べるようになったキャラクター
+}
+
+// Record は1回のプレイの統計を記録に足し、得たコインを加えます
+// 記録を足して条件を満たしたキャラクターは選べるようになります
+func (d *Data) Record(defs *world.Definitions, s world.Stats) Result {
+	r := Result{
+		Coins:           s.Coins,
+		BestScore:       s.Score > d.BestScore,
+		LongestSurvival: s.SurvivalTime > d.LongestSurvival,
+	}
+	d.Runs++
+	d.BestScore = max(d.BestScore, s.Score)
+	d.LongestSurvival = max(d.LongestSurvival, s.SurvivalTime)
+	d.BestLevel = max(d.BestLevel, s.Level)
+	for k, v := range s.Kills {
+		d.Kills[k] += v
+	}
+	d.Coins += s.Coins
+	r.Unlocked = d.Unlock(defs)
+	return r
+}
+
+// Unlock は条件を満たしたキャラクターを選べるようにし、新しく選べるようになったキャラクターを返します
+// 定義を変えて条件が緩くなったときのために、起動したときにも呼びます
+func (d *Data) Unlock(defs *world.Definitions) []world.CharacterType {
+	var unlocked []world.CharacterType
+	for _, c := range defs.Characters {
+		if c.Unlock == nil || slices.Contains(d.Unlocked, c.CharacterType) || !d.meets(*c.Unlock) {
+			continue
+		}
+		d.Unlocked = append(d.Unlocked, c.CharacterType)
+		unlocked = append(unlocked, c.CharacterType)
+	}
+	return unlocked
+}
+
+// IsUnlocked は c を選べるかどうかを返します
+func (d *Data) IsUnlocked(c world.CharacterParams) bool {
+	return c.Unlock == nil || slices.Contains(d.Unlocked, c.CharacterType)
+}
+
+// meets は記録が cond を満たしているかどうかを返します
+func (d *Data) meets(cond world.UnlockCondition) bool {
+	switch cond.Kind {
+	case world.UnlockSurvival:
+		return d.LongestSurvival >= cond.Value
+	case world.UnlockScore:
+		return float64(d.BestScore) >= cond.Value
+	case world.UnlockLevel:
+		return float64(d.BestLevel) >= cond.Value
+	case world.UnlockKills:
+		if cond.Enemy != "" {
+			return float64(d.Kills[cond.Enemy]) >= cond.Value
+		}
+		total := 0
+		for _, n := range d.Kills {
+			total += n
+		}
+		return float64(total) >= cond.Value
+	case world.UnlockRuns:
+		return float64(d.Runs) >= cond.Value
+	}
+	return false
+}
+
+// Buy はコインを払って id の強化のレベルを1つ上げます
+func (d *Data) Buy(defs *world.Definitions, id world.UpgradeType) error {
+	u, ok := defs.Upgrade(id)
+	if !ok {
+		return fmt.Errorf("unknown upgrade %q", id)
+	}
+	level := d.Upgrades[id]
+	if level >= u.MaxLevel {
+		return ErrMaxLevel
+	}
+	price := u.Price(level + 1)
+	if d.Coins < price {
+		return ErrNotEnoughCoins
+	}
+	d.Coins -= price
+	d.Upgrades[id] = level + 1
+	return nil
+}
+
+// UpgradeLevels は買った強化のレベルの複製を返します
+func (d *Data) UpgradeLevels() map[world.UpgradeType]int {
+	return maps.Clone(d.Upgrades)
+}
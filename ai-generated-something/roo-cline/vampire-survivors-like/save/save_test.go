@@ -0,0 +1,193 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"vampire-survivors-like/world"
+)
+
+func TestRecord(t *testing.T) {
+	defs := world.DefaultDefinitions()
+	d := New()
+	r := d.Record(defs, world.Stats{Score: 500, SurvivalTime: 120, Level: 4, Coins: 7, Kills: map[world.EnemyType]int{"normal": 10}})
+	if !r.BestScore || !r.LongestSurvival || r.Coins != 7 {
+		t.Errorf("first Record() = %+v, want new records and 7 coins", r)
+	}
+
+	r = d.Record(defs, world.Stats{Score: 300, SurvivalTime: 200, Level: 3, Coins: 3, Kills: map[world.EnemyType]int{"normal": 5, "fast": 2}})
+	if r.BestScore || !r.LongestSurvival {
+		t.Errorf("second Record() = %+v, want only a new longest survival", r)
+	}
+
+	want := &Data{
+		Version:         Version,
+		Runs:            2,
+		BestScore:       500,
+		LongestSurvival: 200,
+		BestLevel:       4,
+		Kills:           map[world.EnemyType]int{"normal": 15, "fast": 2},
+		Coins:           10,
+		Upgrades:        map[world.UpgradeType]int{},
+		Unlocked:        []world.CharacterType{},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("Data = %+v, want %+v", d, want)
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	// 検証はしないので、キャラクターだけを差し替える
+	defs := world.DefaultDefinitions()
+	defs.Characters = []world.CharacterParams{
+		{CharacterType: "a"},
+		{CharacterType: "b", Unlock: &world.UnlockCondition{Kind: world.UnlockSurvival, Value: 300}},
+		{CharacterType: "c", Unlock: &world.UnlockCondition{Kind: world.UnlockKills, Value: 2, Enemy: "boss"}},
+		{CharacterType: "d", Unlock: &world.UnlockCondition{Kind: world.UnlockKills, Value: 100}},
+		{CharacterType: "e", Unlock: &world.UnlockCondition{Kind: world.UnlockRuns, Value: 2}},
+	}
+	d := New()
+	if !d.IsUnlocked(defs.Characters[0]) {
+		t.Error("a character without a condition is locked")
+	}
+
+	r := d.Record(defs, world.Stats{SurvivalTime: 310, Kills: map[world.EnemyType]int{"boss": 1, "normal": 60}})
+	if !reflect.DeepEqual(r.Unlocked, []world.CharacterType{"b"}) {
+		t.Errorf("first Unlocked = %v, want [b]", r.Unlocked)
+	}
+	// 撃破数は累計で数え、一度選べるようになったキャラクターは返さない
+	r = d.Record(defs, world.Stats{SurvivalTime: 10, Kills: map[world.EnemyType]int{"boss": 1, "normal": 38}})
+	if !reflect.DeepEqual(r.Unlocked, []world.CharacterType{"c", "d", "e"}) {
+		t.Errorf("second Unlocked = %v, want [c d e]", r.Unlocked)
+	}
+	for _, c := range defs.Characters {
+		if !d.IsUnlocked(c) {
+			t.Errorf("%q is still locked", c.CharacterType)
+		}
+	}
+	if got := d.Unlock(defs); got != nil {
+		t.Errorf("Unlock() = %v, want nothing new", got)
+	}
+}
+
+func TestBuy(t *testing.T) {
+	defs := world.DefaultDefinitions()
+	u, _ := defs.Upgrade("max_hp")
+	d := New()
+
+	if err := d.Buy(defs, u.UpgradeType); !errors.Is(err, ErrNotEnoughCoins) {
+		t.Fatalf("Buy() without coins = %v, want ErrNotEnoughCoins", err)
+	}
+
+	// n レベル目は n 倍の値段になる
+	for level := 1; level <= u.MaxLevel; level++ {
+		d.Coins = u.Price(level)
+		if err := d.Buy(defs, u.UpgradeType); err != nil {
+			t.Fatalf("Buy() level %d: %v", level, err)
+		}
+		if d.Coins != 0 || d.Upgrades[u.UpgradeType] != level {
+			t.Fatalf("Coins, level = %d, %d after buying level %d", d.Coins, d.Upgrades[u.UpgradeType], level)
+		}
+	}
+	d.Coins = 1 << 20
+	if err := d.Buy(defs, u.UpgradeType); !errors.Is(err, ErrMaxLevel) {
+		t.Errorf("Buy() at max level = %v, want ErrMaxLevel", err)
+	}
+	if err := d.Buy(defs, "unknown"); err == nil {
+		t.Error("Buy(unknown) succeeded")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	f := File{Path: filepath.Join(t.TempDir(), "sub", "save.json")}
+
+	// まだ保存していなければ空の記録になる
+	d, err := Load(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d, New()) {
+		t.Fatalf("Load(missing) = %+v, want empty", d)
+	}
+
+	d.Record(world.DefaultDefinitions(), world.Stats{Score: 100, SurvivalTime: 30, Coins: 5, Kills: map[world.EnemyType]int{"boss": 1}})
+	d.Upgrades["might"] = 2
+	d.Character = "mage"
+	if err := Save(f, d); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, d) {
+		t.Errorf("Load() = %+v, want %+v", got, d)
+	}
+}
+
+func TestDecodeVersions(t *testing.T) {
+	for _, data := range []string{`{}`, `{"version": 0}`, `{"version": 99}`, `{"version": "1"}`} {
+		if _, err := Decode([]byte(data)); err == nil {
+			t.Errorf("Decode(%s) succeeded, want error", data)
+		}
+	}
+
+	// 書いていない項目は空になる
+	d, err := Decode([]byte(`{"version": 1, "coins": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Coins != 3 || d.Kills == nil || d.Upgrades == nil {
+		t.Errorf("Decode() = %+v", d)
+	}
+}
+
+func TestDecodeVersion1(t *testing.T) {
+	d, err := Decode([]byte(`{"version": 1, "runs": 3, "best_score": 900, "coins": 12, "upgrades": {"might": 1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Version != Version || d.Runs != 3 || d.BestScore != 900 || d.Coins != 12 || d.Upgrades["might"] != 1 {
+		t.Errorf("Decode(v1) = %+v, want the v1 records kept", d)
+	}
+	if d.BestLevel != 0 || d.Unlocked == nil || len(d.Unlocked) != 0 {
+		t.Errorf("Decode(v1) = %+v, want no unlocked characters", d)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	// バージョン1で "gold" だった項目を、バージョン2で "coins" に、バージョン3で2倍にする
+	migrations := []migration{
+		func(raw map[string]json.RawMessage) error {
+			raw["coins"] = raw["gold"]
+			delete(raw, "gold")
+			return nil
+		},
+		func(raw map[string]json.RawMessage) error {
+			var coins int
+			if err := json.Unmarshal(raw["coins"], &coins); err != nil {
+				return err
+			}
+			raw["coins"], _ = json.Marshal(coins * 2)
+			return nil
+		},
+	}
+
+	raw := map[string]json.RawMessage{"version": json.RawMessage("1"), "gold": json.RawMessage("5")}
+	if err := migrate(raw, migrations); err != nil {
+		t.Fatal(err)
+	}
+	if string(raw["version"]) != "3" || string(raw["coins"]) != "10" || raw["gold"] != nil {
+		t.Errorf("migrated = %s", raw)
+	}
+
+	// 途中の変換に失敗したら、どのバージョンからの変換かをエラーに含める
+	raw = map[string]json.RawMessage{"version": json.RawMessage("2"), "coins": json.RawMessage(`"x"`)}
+	if err := migrate(raw, migrations); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("migrate() = %v, want an error from version 2", err)
+	}
+}
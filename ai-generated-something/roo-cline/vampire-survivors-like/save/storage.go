@@ -0,0 +1,54 @@
+package save
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"vampire-survivors-like/atomicfile"
+)
+
+// Storage は記録の保存先です
+// デスクトップではファイル、ブラウザでは localStorage に保存します
+type Storage interface {
+	// Read は保存した内容を返します。まだ保存していなければ fs.ErrNotExist を返します
+	Read() ([]byte, error)
+	Write(data []byte) error
+}
+
+// Load は s から記録を読み込みます。まだ保存していなければ空の記録を返します
+func Load(s Storage) (*Data, error) {
+	data, err := s.Read()
+	if errors.Is(err, fs.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Save は記録を s に保存します
+func Save(s Storage, d *Data) error {
+	data, err := d.Encode()
+	if err != nil {
+		return err
+	}
+	return s.Write(data)
+}
+
+// File はファイルに保存する Storage です
+type File struct {
+	Path string
+}
+
+// Read はファイルの内容を返します
+func (f File) Read() ([]byte, error) {
+	return os.ReadFile(f.Path)
+}
+
+// Write はファイルに書き込みます。ディレクトリがなければ作成します
+// 書き込みの途中で終了しても記録が消えないよう、atomicfile で書き込みます
+func (f File) Write(data []byte) error {
+	return atomicfile.Write(f.Path, append(data, '\n'))
+}
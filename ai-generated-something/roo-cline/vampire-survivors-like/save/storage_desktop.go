@@ -0,0 +1,17 @@
+//go:build !js
+
+package save
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultStorage は記録の既定の保存先として、ユーザーの設定ディレクトリのファイルを返します
+func DefaultStorage() (Storage, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return File{Path: filepath.Join(dir, "vampire-survivors-like", "save.json")}, nil
+}
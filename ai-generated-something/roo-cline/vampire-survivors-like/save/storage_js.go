@@ -0,0 +1,50 @@
+//go:build js
+
+package save
+
+import (
+	"errors"
+	"io/fs"
+	"syscall/js"
+)
+
+// localStorageKey は記録を保存する localStorage のキーです
+const localStorageKey = "vampire-survivors-like/save"
+
+// LocalStorage はブラウザの localStorage に保存する Storage です
+type LocalStorage struct {
+	Key string
+}
+
+// DefaultStorage は記録の既定の保存先として、ブラウザの localStorage を返します
+func DefaultStorage() (Storage, error) {
+	if ls := js.Global().Get("localStorage"); ls.IsUndefined() || ls.IsNull() {
+		return nil, errors.New("localStorage is not available")
+	}
+	return LocalStorage{Key: localStorageKey}, nil
+}
+
+// Read は localStorage に保存した内容を返します
+func (s LocalStorage) Read() ([]byte, error) {
+	v := js.Global().Get("localStorage").Call("getItem", s.Key)
+	if v.IsNull() {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(v.String()), nil
+}
+
+// Write は localStorage に保存します。容量を超えたときなどはエラーを返します
+func (s LocalStorage) Write(data []byte) (err error) {
+	defer func() {
+		// setItem が例外を投げると js.Error で panic する
+		if r := recover(); r != nil {
+			if jsErr, ok := r.(js.Error); ok {
+				err = jsErr
+				return
+			}
+			panic(r)
+		}
+	}()
+	js.Global().Get("localStorage").Call("setItem", s.Key, string(data))
+	return nil
+}
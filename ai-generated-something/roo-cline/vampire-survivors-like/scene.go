@@ -0,0 +1,259 @@
+package main
+
+import (
+	"image/color"
+	"reflect"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/ui"
+	"vampire-survivors-like/world"
+)
+
+// scene は画面の状態です。Game は常にいずれか1つの scene にいます
+//
+//	title → characters → playing ⇄ levelUp
+//	          ⇅  ↘
+//	       paused  results → playing / title
+//	title, paused → options ⇄ controls
+//	options → 元の scene
+//	title ⇄ upgrades, characters
+//	playing, levelUp → replayEnded（リプレイの再生が終わったとき）
+//
+// リプレイの再生中はタイトル画面に戻らず、再生を終えたらゲームを終了します
+// 再生したゲームの続きを、記録にない入力でそのまま遊べないようにするためです
+//
+// scene の切り替えは changeScene だけで行い、切り替えのたびに exit と enter が呼ばれます
+type scene interface {
+	enter(g *Game)
+	exit(g *Game)
+	update(g *Game)
+	draw(g *Game, screen *ebiten.Image)
+}
+
+// changeScene は今の scene を抜けて next に切り替えます
+func (g *Game) changeScene(next scene) {
+	if g.scene != nil {
+		g.scene.exit(g)
+	}
+	g.scene = next
+	next.enter(g)
+}
+
+// stepWorld は入力を1ティック分取り出してゲームを進めます
+// リプレイの再生が終わっていたら何もせずに false を返します
+func (g *Game) stepWorld() bool {
+	in, ok := g.input.Next()
+	if !ok {
+		return false
+	}
+	g.applyInput(in)
+	return true
+}
+
+// live はプレイヤーが操作しているか（リプレイの再生中でないか）を返します
+func (g *Game) live() bool {
+	_, ok := g.input.(ControlsInput)
+	return ok
+}
+
+// applyInput は in でゲームを1ティック進め、記録中なら入力を記録します
+// ゲームがリスタートしたら、前のプレイの演出を新しいプレイに持ち越さないよう消します
+func (g *Game) applyInput(in world.Input) {
+	if g.recorder != nil {
+		g.recorder.Record(in)
+	}
+	restarted := in.Restart && g.world.GameOver
+	g.world.Step(in)
+	if restarted {
+		g.fx.Clear()
+	}
+	g.playEvents()
+	g.updateEffects()
+}
+
+// followWorld はゲームの状態（スキル選択中・ゲームオーバー）が変わっていれば、合った scene に切り替えます
+func (g *Game) followWorld() {
+	var next scene = playingScene{}
+	switch {
+	case g.world.GameOver:
+		next = &resultsScene{}
+	case g.world.ChoosingSkill:
+		next = &levelUpScene{}
+	}
+	if reflect.TypeOf(next) != reflect.TypeOf(g.scene) {
+		g.changeScene(next)
+	}
+}
+
+// タイトル画面の項目
+const (
+	titleStart = iota
+	titleUpgrades
+	titleOptions
+	titleCount
+)
+
+// ポーズ画面の項目
+const (
+	pauseResume = iota
+	pauseOptions
+	pauseCount
+)
+
+// titleScene はタイトル画面です
+type titleScene struct {
+	menu menu
+}
+
+func (*titleScene) enter(g *Game) {
+	g.sound.PlayMusic("title")
+}
+
+func (*titleScene) exit(*Game) {}
+
+func (s *titleScene) update(g *Game) {
+	switch s.menu.update(g.controls, titleCount) {
+	case titleStart:
+		// 入力の記録中は記録を始めたときのキャラクターで遊ぶので、選ばせない
+		if g.recorder != nil || len(g.world.NextDefinitions().Characters) == 0 {
+			g.startRun()
+			return
+		}
+		g.changeScene(&characterScene{back: s})
+	case titleUpgrades:
+		g.changeScene(&upgradesScene{back: s})
+	case titleOptions:
+		g.changeScene(&optionsScene{back: s})
+	}
+}
+
+func (s *titleScene) draw(g *Game, screen *ebiten.Image) {
+	screen.Fill(color.RGBA{16, 20, 16, 255})
+	cx, cy := float64(world.ScreenWidth)/2, float64(world.ScreenHeight)/2
+	ui.Centered(screen, g.text.T(i18n.TitleName), cx, cy-80, ui.TextOptions{Scale: 3, Color: color.RGBA{255, 80, 80, 255}, Outline: outlineColor})
+
+	// これまでの最高記録と使えるコイン
+	seconds := int(g.records.LongestSurvival)
+	ui.Centered(screen, g.text.T(i18n.TitleRecords, g.records.BestScore, seconds/60, seconds%60), cx, cy-30, ui.TextOptions{Color: hintColor})
+	ui.Centered(screen, g.text.T(i18n.Coins, g.records.Coins), cx, cy-10, ui.TextOptions{Color: selectedColor})
+
+	s.menu.draw(screen, []string{g.text.T(i18n.MenuStart), g.text.T(i18n.MenuUpgrades), g.text.T(i18n.MenuOptions)}, cx, cy+40, 30)
+	ui.Centered(screen, g.text.T(i18n.MenuHint), cx, world.ScreenHeight-40, ui.TextOptions{Color: hintColor})
+}
+
+// playingScene はゲームを進めている画面です
+type playingScene struct{}
+
+func (playingScene) enter(*Game) {}
+func (playingScene) exit(*Game)  {}
+
+func (playingScene) update(g *Game) {
+	if g.controls.JustPressed(ActionPause) {
+		g.changeScene(&pausedScene{})
+		return
+	}
+	if !g.stepWorld() {
+		g.changeScene(&replayEndedScene{})
+		return
+	}
+	g.sound.PlayMusic(g.stageMusic())
+	g.followWorld()
+}
+
+func (playingScene) draw(g *Game, screen *ebiten.Image) {
+	g.drawWorld(screen)
+}
+
+// pausedScene は一時停止中の画面です。ゲームは進めません
+type pausedScene struct {
+	menu menu
+}
+
+func (*pausedScene) enter(*Game) {}
+func (*pausedScene) exit(*Game)  {}
+
+func (s *pausedScene) update(g *Game) {
+	if g.controls.JustPressed(ActionPause) || g.controls.JustPressed(ActionCancel) {
+		g.changeScene(playingScene{})
+		return
+	}
+	switch s.menu.update(g.controls, pauseCount) {
+	case pauseResume:
+		g.changeScene(playingScene{})
+	case pauseOptions:
+		g.changeScene(&optionsScene{back: s})
+	}
+}
+
+func (s *pausedScene) draw(g *Game, screen *ebiten.Image) {
+	g.drawWorld(screen)
+	fillRect(screen, 0, 0, world.ScreenWidth, world.ScreenHeight, color.RGBA{0, 0, 0, 160})
+	cx, cy := float64(world.ScreenWidth)/2, float64(world.ScreenHeight)/2
+	ui.Centered(screen, g.text.T(i18n.PausedTitle), cx, cy-50, ui.TextOptions{Scale: 2, Outline: outlineColor})
+	s.menu.draw(screen, []string{g.text.T(i18n.MenuResume), g.text.T(i18n.MenuOptions)}, cx, cy+10, 30)
+}
+
+// levelUpScene はレベルアップ時のスキル選択画面です
+// 選択もゲームの入力なので、ここでもゲームを進めて記録します
+// 番号のキーのほか、上下で選んで決定しても選べます。除外するのは上下で選んでいる選択肢です
+type levelUpScene struct {
+	menu menu
+}
+
+func (*levelUpScene) enter(*Game) {}
+func (*levelUpScene) exit(*Game)  {}
+
+func (s *levelUpScene) update(g *Game) {
+	in, ok := g.input.Next()
+	if !ok {
+		g.changeScene(&replayEndedScene{})
+		return
+	}
+	if g.live() {
+		if i := s.menu.update(g.controls, len(g.world.SkillOptions)); i >= 0 && in.Choice == 0 {
+			in.Choice = i + 1
+		}
+		if in.SkillAction == world.SkillActionBanish && in.Choice == 0 {
+			in.Choice = s.menu.cursor + 1
+		}
+		if in.Choice > 0 || in.SkillAction != world.SkillActionNone {
+			// 選択肢が変わるので先頭の選択肢から
+			s.menu.cursor = 0
+		}
+	}
+	g.applyInput(in)
+	g.followWorld()
+}
+
+func (s *levelUpScene) draw(g *Game, screen *ebiten.Image) {
+	cursor := -1
+	if g.live() {
+		cursor = s.menu.cursor
+	}
+	g.drawSkillSelection(screen, cursor)
+}
+
+// replayEndedScene はリプレイの再生が終わったことを知らせる画面です。決定か戻るでゲームを終了します
+type replayEndedScene struct{}
+
+func (replayEndedScene) enter(g *Game) {
+	g.sound.PlayMusic("")
+}
+
+func (replayEndedScene) exit(*Game) {}
+
+func (replayEndedScene) update(g *Game) {
+	if g.controls.JustPressed(ActionConfirm) || g.controls.JustPressed(ActionCancel) {
+		g.quit = true
+	}
+}
+
+func (replayEndedScene) draw(g *Game, screen *ebiten.Image) {
+	g.drawWorld(screen)
+	fillRect(screen, 0, 0, world.ScreenWidth, world.ScreenHeight, color.RGBA{0, 0, 0, 160})
+	cx, cy := float64(world.ScreenWidth)/2, float64(world.ScreenHeight)/2
+	ui.Centered(screen, g.text.T(i18n.ReplayEnded), cx, cy-20, ui.TextOptions{Scale: 2, Outline: outlineColor})
+	ui.Centered(screen, g.text.T(i18n.ReplayEndedHint), cx, cy+30, ui.TextOptions{Color: hintColor})
+}
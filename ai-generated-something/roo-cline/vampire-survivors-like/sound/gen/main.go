@@ -0,0 +1,185 @@
+// gen は効果音と BGM を合成して WAV ファイルに書き出します
+//
+//	go generate ./sound
+//
+// 効果音は data/se/、BGM は data/bgm/ に書き出します。BGM は継ぎ目なくループするように作ります
+package main
+
+import (
+	"encoding/binary"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const (
+	seRate  = 22050 // 効果音のサンプリング周波数
+	bgmRate = 11025 // BGM のサンプリング周波数（ファイルを小さくするため低め）
+)
+
+func main() {
+	se := map[string][]float64{
+		"weapon/melee":  swoosh(),
+		"weapon/ranged": sweep(square(0.5), 1200, 400, 0.12, 0.5),
+		"weapon/aura":   hum(),
+		"weapon/spiral": sweep(triangle, 400, 900, 0.15, 0.5),
+		"enemy_death":   explosion(0.18, 300, 0.5),
+		"boss_death":    explosion(0.8, 160, 0.8),
+		"level_up":      arpeggio([]float64{72, 76, 79, 84}, 0.08),
+		"player_hit":    hit(),
+	}
+	for name, samples := range se {
+		write(filepath.Join("data", "se", name+".wav"), seRate, samples)
+	}
+
+	for name, t := range tracks {
+		write(filepath.Join("data", "bgm", name+".wav"), bgmRate, t.render())
+	}
+}
+
+// wave は位相 (0〜1) での波形の値 (-1〜1) です
+type wave func(phase float64) float64
+
+func square(duty float64) wave {
+	return func(p float64) float64 {
+		if p < duty {
+			return 1
+		}
+		return -1
+	}
+}
+
+func triangle(p float64) float64 {
+	return 1 - 4*math.Abs(p-0.5)
+}
+
+func sine(p float64) float64 {
+	return math.Sin(2 * math.Pi * p)
+}
+
+// noise は決まった順の乱数を返す雑音です。毎回同じファイルができるようにします
+type noise struct{ state uint32 }
+
+func (n *noise) next() float64 {
+	n.state = n.state*1664525 + 1013904223
+	return float64(n.state>>8)/float64(1<<23) - 1
+}
+
+// decay は t 秒後の減衰した音量です。length 秒で十分小さくなります
+func decay(t, length float64) float64 {
+	return math.Exp(-5 * t / length)
+}
+
+// sweep は周波数を from から to に変えながら length 秒鳴らします
+func sweep(w wave, from, to, length, volume float64) []float64 {
+	n := samples(length, seRate)
+	out := make([]float64, n)
+	phase := 0.0
+	for i := range out {
+		t := float64(i) / seRate
+		f := from + (to-from)*t/length
+		phase = math.Mod(phase+f/seRate, 1)
+		out[i] = w(phase) * decay(t, length) * volume
+	}
+	return out
+}
+
+func swoosh() []float64 {
+	const length = 0.15
+	n := noise{state: 1}
+	out := make([]float64, samples(length, seRate))
+	low := 0.0
+	for i := range out {
+		t := float64(i) / seRate
+		// 雑音をなめらかにして、だんだん高くなる風切り音にする
+		k := 0.05 + 0.4*t/length
+		low += (n.next() - low) * k
+		out[i] = low * math.Sin(math.Pi*t/length) * 0.8
+	}
+	return out
+}
+
+func hum() []float64 {
+	const length = 0.25
+	out := make([]float64, samples(length, seRate))
+	for i := range out {
+		t := float64(i) / seRate
+		v := sine(math.Mod(220*t, 1)) + 0.5*sine(math.Mod(330*t, 1))
+		out[i] = v * math.Sin(math.Pi*t/length) * 0.2
+	}
+	return out
+}
+
+func explosion(length, pitch, volume float64) []float64 {
+	n := noise{state: 7}
+	tone := sweep(square(0.5), pitch, pitch/4, length, 0.4)
+	out := make([]float64, len(tone))
+	low := 0.0
+	for i := range out {
+		t := float64(i) / seRate
+		low += (n.next() - low) * 0.3
+		out[i] = (tone[i] + low*decay(t, length)) * volume
+	}
+	return out
+}
+
+func hit() []float64 {
+	out := sweep(square(0.5), 150, 90, 0.2, 0.6)
+	n := noise{state: 3}
+	for i := range out {
+		t := float64(i) / seRate
+		out[i] += n.next() * decay(t, 0.08) * 0.3
+	}
+	return out
+}
+
+func arpeggio(notes []float64, step float64) []float64 {
+	per := int(step * seRate)
+	out := make([]float64, per*len(notes)+per*3)
+	for i, note := range notes {
+		f := freq(note)
+		for j := 0; j < per*4 && i*per+j < len(out); j++ {
+			t := float64(j) / seRate
+			out[i*per+j] += square(0.25)(math.Mod(f*t, 1)) * decay(t, step*4) * 0.3
+		}
+	}
+	return out
+}
+
+// freq は MIDI のノート番号の周波数を返します
+func freq(note float64) float64 {
+	return 440 * math.Pow(2, (note-69)/12)
+}
+
+// write は samples (-1〜1) を16ビットモノラルの WAV ファイルに書き出します
+func write(path string, rate int, samples []float64) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatal(err)
+	}
+	data := make([]byte, 44+len(samples)*2)
+	copy(data[0:], "RIFF")
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	copy(data[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(data[16:], 16)
+	binary.LittleEndian.PutUint16(data[20:], 1) // リニア PCM
+	binary.LittleEndian.PutUint16(data[22:], 1) // モノラル
+	binary.LittleEndian.PutUint32(data[24:], uint32(rate))
+	binary.LittleEndian.PutUint32(data[28:], uint32(rate*2))
+	binary.LittleEndian.PutUint16(data[32:], 2)
+	binary.LittleEndian.PutUint16(data[34:], 16)
+	copy(data[36:], "data")
+	binary.LittleEndian.PutUint32(data[40:], uint32(len(samples)*2))
+	for i, s := range samples {
+		v := int16(math.Round(max(-1, min(1, s)) * math.MaxInt16))
+		binary.LittleEndian.PutUint16(data[44+i*2:], uint16(v))
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// samples は rate で length 秒分のサンプル数です
+func samples(length float64, rate int) int {
+	return int(length * float64(rate))
+}
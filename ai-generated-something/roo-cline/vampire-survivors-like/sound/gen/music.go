@@ -0,0 +1,139 @@
+package main
+
+import "math"
+
+// track は BGM の1曲です。16分音符を1ステップとして bars 小節を繰り返します
+type track struct {
+	bpm    float64
+	chords []float64 // 小節ごとの和音の根音（MIDI のノート番号）
+	minor  bool
+	lead   []int // 1小節16ステップの旋律。和音の構成音の番号（-1 は休み）
+	bass   []int // 1小節16ステップのベース。根音からの半音数（-1 は休み）
+	drums  bool
+	volume float64
+}
+
+var tracks = map[string]track{
+	"title": {
+		bpm:    100,
+		chords: []float64{57, 53, 48, 55}, // Am F C G
+		minor:  true,
+		lead:   []int{0, -1, 1, -1, 2, -1, 3, -1, 2, -1, 1, -1, 2, -1, 1, -1},
+		bass:   []int{0, -1, -1, -1, -1, -1, -1, -1, 7, -1, -1, -1, -1, -1, -1, -1},
+		volume: 0.9,
+	},
+	"stage": {
+		bpm:    140,
+		chords: []float64{52, 52, 48, 50}, // Em Em C D
+		minor:  true,
+		lead:   []int{3, -1, 2, -1, 1, 2, -1, 0, -1, 1, -1, 2, 3, -1, 4, -1},
+		bass:   []int{0, -1, 0, -1, 12, -1, 0, -1, 0, -1, 0, -1, 12, -1, 7, -1},
+		drums:  true,
+		volume: 0.6,
+	},
+	"boss": {
+		bpm:    160,
+		chords: []float64{50, 50, 51, 49}, // Dm Dm E♭ D♭
+		minor:  true,
+		lead:   []int{4, 3, -1, 4, -1, 2, 3, -1, 4, -1, 5, 4, 3, -1, 2, 1},
+		bass:   []int{0, 0, 12, 0, 0, 12, 0, 0, 0, 0, 12, 0, 0, 12, 7, 6},
+		drums:  true,
+		volume: 0.6,
+	},
+}
+
+// tone は和音の根音から n 番目の構成音のノート番号です（3和音をオクターブ上に重ねる）
+func (t track) tone(root float64, n int) float64 {
+	third := 4.0
+	if t.minor {
+		third = 3
+	}
+	intervals := []float64{0, third, 7}
+	return root + intervals[n%3] + 12*float64(n/3)
+}
+
+// render は曲全体のサンプルを作ります
+// 最後の音の余韻は先頭に重ねるので、ループしても継ぎ目が聞こえません
+func (t track) render() []float64 {
+	step := int(bgmRate * 60 / t.bpm / 4)
+	steps := 16 * len(t.chords)
+	out := make([]float64, step*steps)
+	add := func(start int, samples []float64) {
+		for i, s := range samples {
+			out[(start+i)%len(out)] += s
+		}
+	}
+	hats := noise{state: 11}
+	for i := range steps {
+		root := t.chords[i/16]
+		start := i * step
+		if n := t.lead[i%16]; n >= 0 {
+			add(start, note(square(0.25), freq(t.tone(root+12, n)), float64(step)*2/bgmRate, 0.18))
+		}
+		if n := t.bass[i%16]; n >= 0 {
+			add(start, note(triangle, freq(root-12+float64(n)), float64(step)*3/bgmRate, 0.35))
+		}
+		if !t.drums {
+			continue
+		}
+		switch i % 8 {
+		case 0:
+			add(start, kick())
+		case 4:
+			add(start, snare(&hats))
+		}
+		if i%2 == 0 {
+			add(start, hat(&hats))
+		}
+	}
+	for i := range out {
+		out[i] *= t.volume
+	}
+	return out
+}
+
+// note は length 秒の音を作ります。始まりと終わりを少しなめらかにして雑音を防ぎます
+func note(w wave, f, length, volume float64) []float64 {
+	out := make([]float64, samples(length, bgmRate))
+	const fade = 0.005
+	for i := range out {
+		t := float64(i) / bgmRate
+		env := decay(t, length*2) * min(1, t/fade, (length-t)/fade)
+		out[i] = w(math.Mod(f*t, 1)) * env * volume
+	}
+	return out
+}
+
+func kick() []float64 {
+	const length = 0.12
+	out := make([]float64, samples(length, bgmRate))
+	phase := 0.0
+	for i := range out {
+		t := float64(i) / bgmRate
+		phase = math.Mod(phase+(120-70*t/length)/bgmRate, 1)
+		out[i] = sine(phase) * decay(t, length) * 0.6
+	}
+	return out
+}
+
+func snare(n *noise) []float64 {
+	const length = 0.1
+	out := make([]float64, samples(length, bgmRate))
+	for i := range out {
+		out[i] = n.next() * decay(float64(i)/bgmRate, length) * 0.25
+	}
+	return out
+}
+
+func hat(n *noise) []float64 {
+	const length = 0.03
+	out := make([]float64, samples(length, bgmRate))
+	prev := 0.0
+	for i := range out {
+		// 差分を取って高い音だけにする
+		v := n.next()
+		out[i] = (v - prev) * decay(float64(i)/bgmRate, length) * 0.08
+		prev = v
+	}
+	return out
+}
@@ -0,0 +1,272 @@
+// Package sound は埋め込みの効果音と BGM を鳴らします
+//
+// 効果音は data/se/、BGM は data/bgm/ にある WAV か Ogg Vorbis のファイルで、
+// ファイル名（拡張子を除いたパス）で引きます
+package sound
+
+//go:generate go run ./gen
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+//go:embed data
+var data embed.FS
+
+// 再生するサンプリング周波数。ファイルはこの周波数に変換して読み込む
+const sampleRate = 44100
+
+// 1秒分のバイト数（16ビットステレオ）
+const bytesPerSecond = sampleRate * 4
+
+// 効果音の同時発音の制限
+// 大量の敵を一度に倒しても音が割れたり重くなったりしないよう、鳴らす数を絞ります
+const (
+	maxVoices      = 16 // 全体で同時に鳴らせる数
+	maxVoicesPer   = 4  // 同じ効果音を同時に鳴らせる数
+	minInterval    = 3  // 同じ効果音を続けて鳴らすときに空けるティック数
+	sfxHeadroom    = 0.6
+	crossfadeTicks = 60 // BGM の切り替えにかけるティック数
+)
+
+// Manager は効果音と BGM の再生を管理します
+// Update を毎ティック呼んでください
+type Manager struct {
+	ctx    *audio.Context
+	sounds map[string][]byte // 効果音の名前ごとの PCM
+	music  map[string][]byte // BGM の名前ごとの PCM
+
+	voices   []*voice
+	lastPlay map[string]int // 効果音を最後に鳴らしたティック
+	tick     int
+
+	track  *track   // 今の BGM
+	fading []*track // 消えていく途中の BGM
+
+	sfxVolume, musicVolume float64
+}
+
+// voice は鳴っている効果音です
+type voice struct {
+	name   string
+	player *audio.Player
+}
+
+// track は鳴っている BGM です。gain を 0 から 1 に上げて始め、0 に下げて止めます
+type track struct {
+	name   string
+	player *audio.Player
+	gain   float64
+}
+
+// Load は埋め込みの効果音と BGM を読み込みます
+// 起動時に1回だけ呼び、結果を使い回してください
+func Load() (*Manager, error) {
+	m := &Manager{
+		ctx:         audio.NewContext(sampleRate),
+		lastPlay:    make(map[string]int),
+		sfxVolume:   1,
+		musicVolume: 1,
+	}
+	var err error
+	if m.sounds, err = loadDir("data/se"); err != nil {
+		return nil, err
+	}
+	if m.music, err = loadDir("data/bgm"); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// loadDir は dir 以下の音声ファイルをすべて PCM に変換して読み込みます
+func loadDir(dir string) (map[string][]byte, error) {
+	sounds := make(map[string][]byte)
+	err := fs.WalkDir(data, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := path.Ext(p)
+		f, err := data.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var stream io.Reader
+		switch ext {
+		case ".wav":
+			stream, err = wav.DecodeWithSampleRate(sampleRate, f)
+		case ".ogg":
+			stream, err = vorbis.DecodeWithSampleRate(sampleRate, f)
+		default:
+			return fmt.Errorf("%s: unsupported audio format", p)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		pcm, err := io.ReadAll(stream)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(p, dir+"/"), ext)
+		sounds[name] = pcm
+		return nil
+	})
+	return sounds, err
+}
+
+// Has は name の効果音があるかを返します
+func (m *Manager) Has(name string) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m.sounds[name]
+	return ok
+}
+
+// Play は name の効果音を1回鳴らします。効果音がなければ何もしません
+// 同じ効果音が鳴りすぎているときは鳴らさず、全体で鳴らしすぎているときは最も古い音を止めます
+// m が nil（読み込みに失敗したとき）でも呼べます
+func (m *Manager) Play(name string) {
+	if m == nil {
+		return
+	}
+	pcm, ok := m.sounds[name]
+	if !ok {
+		return
+	}
+	if last, ok := m.lastPlay[name]; ok && m.tick-last < minInterval {
+		return
+	}
+	same := 0
+	for _, v := range m.voices {
+		if v.name == name {
+			same++
+		}
+	}
+	if same >= maxVoicesPer {
+		return
+	}
+	if len(m.voices) >= maxVoices {
+		// 最も古い音を止める。詰め直して同じ配列を使い続ける
+		m.voices[0].player.Close()
+		m.voices = slices.Delete(m.voices, 0, 1)
+	}
+
+	p := m.ctx.NewPlayerFromBytes(pcm)
+	p.SetVolume(m.sfxVolume * sfxHeadroom)
+	p.Play()
+	m.voices = append(m.voices, &voice{name: name, player: p})
+	m.lastPlay[name] = m.tick
+}
+
+// PlayMusic は name の BGM に切り替えます。今の BGM とは重ねながら入れ替えます
+// 同じ BGM が鳴っていれば何もしません。空の名前か存在しない名前なら BGM を止めます
+// m が nil でも呼べます
+func (m *Manager) PlayMusic(name string) {
+	if m == nil {
+		return
+	}
+	if m.track != nil {
+		if m.track.name == name {
+			return
+		}
+		m.fading = append(m.fading, m.track)
+		m.track = nil
+	}
+	pcm, ok := m.music[name]
+	if !ok {
+		return
+	}
+
+	// 同じ BGM が消えていく途中なら、最初からではなくそのまま戻す
+	for i, t := range m.fading {
+		if t.name == name {
+			m.track = t
+			m.fading = slices.Delete(m.fading, i, i+1)
+			return
+		}
+	}
+	loop := audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm)))
+	p, err := m.ctx.NewPlayer(loop)
+	if err != nil {
+		return
+	}
+	m.track = &track{name: name, player: p}
+	m.track.apply(m.musicVolume)
+	p.Play()
+}
+
+// SetSFXVolume は効果音の音量 (0〜1) を設定します
+func (m *Manager) SetSFXVolume(v float64) {
+	if m == nil {
+		return
+	}
+	m.sfxVolume = min(max(v, 0), 1)
+}
+
+// SetMusicVolume は BGM の音量 (0〜1) を設定します
+func (m *Manager) SetMusicVolume(v float64) {
+	if m == nil {
+		return
+	}
+	m.musicVolume = min(max(v, 0), 1)
+	if m.track != nil {
+		m.track.apply(m.musicVolume)
+	}
+	for _, t := range m.fading {
+		t.apply(m.musicVolume)
+	}
+}
+
+// Update は鳴り終わった効果音を片付け、BGM の切り替えを進めます
+func (m *Manager) Update() {
+	if m == nil {
+		return
+	}
+	m.tick++
+
+	voices := m.voices[:0]
+	for _, v := range m.voices {
+		if v.player.IsPlaying() {
+			voices = append(voices, v)
+		} else {
+			v.player.Close()
+		}
+	}
+	clear(m.voices[len(voices):])
+	m.voices = voices
+
+	const step = 1.0 / crossfadeTicks
+	if t := m.track; t != nil && t.gain < 1 {
+		t.gain = min(t.gain+step, 1)
+		t.apply(m.musicVolume)
+	}
+	fading := m.fading[:0]
+	for _, t := range m.fading {
+		t.gain -= step
+		if t.gain <= 0 {
+			t.player.Close()
+			continue
+		}
+		t.apply(m.musicVolume)
+		fading = append(fading, t)
+	}
+	clear(m.fading[len(fading):])
+	m.fading = fading
+}
+
+func (t *track) apply(volume float64) {
+	t.player.SetVolume(t.gain * volume)
+}
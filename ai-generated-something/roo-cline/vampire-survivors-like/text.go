@@ -0,0 +1,43 @@
+package main
+
+import (
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/world"
+)
+
+// skillText はスキル選択肢の説明を現在の言語で返します
+// 定義ファイルで追加された項目で訳がないものは、定義ファイルの文言を使います
+func (g *Game) skillText(skill world.SkillOption) string {
+	defs := g.world.Definitions()
+	name := g.text.Or(i18n.Skill(skill.Type), skill.Description)
+	switch skill.Type {
+	case world.SkillNewWeapon:
+		w, ok := defs.Weapon(skill.Weapon)
+		if !ok {
+			break
+		}
+		return g.text.T(i18n.SkillNewWeaponDetail, name, g.text.Or(i18n.WeaponName(w.WeaponType), w.Name))
+	case world.SkillWeaponUpgrade:
+		w, ok := defs.Weapon(skill.Weapon)
+		if !ok || skill.Level < 2 || skill.Level-2 >= len(w.Levels) {
+			break
+		}
+		return g.text.T(i18n.SkillDetail, name,
+			g.text.Or(i18n.WeaponName(w.WeaponType), w.Name), skill.Level,
+			g.text.Or(i18n.WeaponLevel(w.WeaponType, skill.Level), w.Levels[skill.Level-2].Description))
+	case world.SkillPassive:
+		p, ok := defs.Passive(skill.Passive)
+		if !ok {
+			break
+		}
+		return g.text.T(i18n.SkillDetail, name,
+			g.text.Or(i18n.PassiveName(p.PassiveType), p.Name), skill.Level,
+			g.text.Or(i18n.PassiveDescription(p.PassiveType), p.Description))
+	}
+	return name
+}
+
+// passiveName はパッシブアイテムの名前を現在の言語で返します
+func (g *Game) passiveName(p world.PassiveParams) string {
+	return g.text.Or(i18n.PassiveName(p.PassiveType), p.Name)
+}
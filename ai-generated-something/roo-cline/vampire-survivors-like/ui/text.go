@@ -0,0 +1,146 @@
+// Package ui は HUD やメニューの文字を描画します
+//
+// 日本語を含む文字を描けるよう、埋め込みのビットマップフォントを text/v2 で使います
+package ui
+
+import (
+	"image/color"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/bitmapfont/v3"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// 埋め込みのフォント (12px)。全角の記号も全角の幅で描きます
+var face text.Face = text.NewGoXFace(bitmapfont.FaceEA)
+
+// 1行の高さ（倍率1のとき）
+const lineHeight = 16
+
+// Align は文字の横方向の揃え方です
+type Align int
+
+const (
+	AlignStart  Align = iota // x を左端にする
+	AlignCenter              // x を中央にする
+	AlignEnd                 // x を右端にする
+)
+
+// TextOptions は文字の描き方です
+type TextOptions struct {
+	Scale   float64     // 拡大率。ビットマップフォントなので整数が綺麗に見える（0 なら 1）
+	Color   color.Color // nil なら白
+	Align   Align
+	Outline color.Color // nil でなければこの色で縁取りする
+	Width   float64     // 0 より大きければこの幅で折り返す
+}
+
+func (o TextOptions) scale() float64 {
+	if o.Scale <= 0 {
+		return 1
+	}
+	return o.Scale
+}
+
+// Text は s を (x, y) を上端として描画します。s の改行と Width による折り返しで複数行になります
+func Text(dst *ebiten.Image, s string, x, y float64, opts TextOptions) {
+	scale := opts.scale()
+	lines := strings.Split(s, "\n")
+	if opts.Width > 0 {
+		lines = Wrap(s, opts.Width, scale)
+	}
+
+	clr := opts.Color
+	if clr == nil {
+		clr = color.White
+	}
+	for i, line := range lines {
+		ly := y + float64(i)*lineHeight*scale
+		if opts.Outline != nil {
+			// 8方向にずらして描いてから本体を重ねる
+			for _, d := range [][2]float64{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+				drawLine(dst, line, x+d[0]*scale, ly+d[1]*scale, scale, opts.Align, opts.Outline)
+			}
+		}
+		drawLine(dst, line, x, ly, scale, opts.Align, clr)
+	}
+}
+
+// Centered は s を (x, y) を中心として描画します
+func Centered(dst *ebiten.Image, s string, x, y float64, opts TextOptions) {
+	opts.Align = AlignCenter
+	_, h := Measure(s, opts)
+	Text(dst, s, x, y-h/2, opts)
+}
+
+func drawLine(dst *ebiten.Image, line string, x, y, scale float64, align Align, clr color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(clr)
+	switch align {
+	case AlignCenter:
+		op.PrimaryAlign = text.AlignCenter
+	case AlignEnd:
+		op.PrimaryAlign = text.AlignEnd
+	}
+	text.Draw(dst, line, face, op)
+}
+
+// Measure は s を opts で描いたときの幅と高さを返します
+func Measure(s string, opts TextOptions) (width, height float64) {
+	scale := opts.scale()
+	lines := strings.Split(s, "\n")
+	if opts.Width > 0 {
+		lines = Wrap(s, opts.Width, scale)
+	}
+	for _, line := range lines {
+		width = max(width, text.Advance(line, face)*scale)
+	}
+	return width, float64(len(lines)) * lineHeight * scale
+}
+
+// Wrap は s を拡大率 scale で描いたときに width に収まるように行に分けます
+// 日本語は文字の間で、英語は空白の位置で折り返します
+func Wrap(s string, width, scale float64) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		lines = append(lines, wrapLine(para, width/scale)...)
+	}
+	return lines
+}
+
+func wrapLine(s string, width float64) []string {
+	var lines []string
+	runes := []rune(s)
+	for len(runes) > 0 {
+		// 収まる文字数を求める
+		n := 0
+		for n < len(runes) && text.Advance(string(runes[:n+1]), face) <= width {
+			n++
+		}
+		if n == len(runes) {
+			lines = append(lines, string(runes))
+			break
+		}
+		n = max(n, 1)
+
+		// 単語の途中で切らないよう、直前の空白で折り返す
+		if !unicode.IsSpace(runes[n]) {
+			for i := n - 1; i > 0; i-- {
+				if unicode.IsSpace(runes[i]) {
+					n = i
+					break
+				}
+			}
+		}
+		lines = append(lines, strings.TrimRightFunc(string(runes[:n]), unicode.IsSpace))
+		runes = []rune(strings.TrimLeftFunc(string(runes[n:]), unicode.IsSpace))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "")
+	}
+	return lines
+}
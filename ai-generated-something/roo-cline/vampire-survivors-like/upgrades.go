@@ -0,0 +1,125 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"vampire-survivors-like/i18n"
+	"vampire-survivors-like/save"
+	"vampire-survivors-like/ui"
+	"vampire-survivors-like/world"
+)
+
+// upgradesScene はコインで永続的な強化を買う画面です。閉じると back の scene に戻ります
+type upgradesScene struct {
+	back    scene
+	menu    menu
+	message string // 買えなかった理由
+}
+
+func (*upgradesScene) enter(*Game) {}
+func (*upgradesScene) exit(*Game)  {}
+
+func (s *upgradesScene) update(g *Game) {
+	if g.controls.JustPressed(ActionCancel) {
+		g.changeScene(s.back)
+		return
+	}
+	upgrades := g.world.NextDefinitions().Upgrades
+	i := s.menu.update(g.controls, len(upgrades))
+	if i < 0 {
+		return
+	}
+	s.message = ""
+	switch err := g.records.Buy(g.world.NextDefinitions(), upgrades[i].UpgradeType); {
+	case errors.Is(err, save.ErrNotEnoughCoins):
+		s.message = g.text.T(i18n.UpgradesNotEnough)
+	case err != nil:
+		// 最大レベルの強化は何もしない
+	default:
+		g.sound.Play("level_up")
+		g.saveRecords()
+	}
+}
+
+func (s *upgradesScene) draw(g *Game, screen *ebiten.Image) {
+	screen.Fill(color.RGBA{16, 20, 16, 255})
+	cx := float64(world.ScreenWidth) / 2
+	ui.Centered(screen, g.text.T(i18n.UpgradesTitle), cx, 50, ui.TextOptions{Scale: 2, Outline: outlineColor})
+	ui.Centered(screen, g.text.T(i18n.Coins, g.records.Coins), cx, 85, ui.TextOptions{Color: selectedColor})
+
+	const top, spacing, left = 120, 40, 120
+	for i, u := range g.world.NextDefinitions().Upgrades {
+		y := float64(top + i*spacing)
+		opts := ui.TextOptions{}
+		if i == s.menu.cursor {
+			opts.Color = selectedColor
+		}
+		level := g.records.Upgrades[u.UpgradeType]
+		ui.Text(screen, g.upgradeName(u), left, y, opts)
+		ui.Text(screen, g.text.T(i18n.UpgradesLevel, min(level, u.MaxLevel), u.MaxLevel), left+200, y, opts)
+
+		price := g.text.T(i18n.UpgradesMax)
+		if level < u.MaxLevel {
+			price = g.text.T(i18n.UpgradesPrice, u.Price(level+1))
+		}
+		end := opts
+		end.Align = ui.AlignEnd
+		ui.Text(screen, price, world.ScreenWidth-left, y, end)
+
+		desc := g.text.Or(i18n.UpgradeDescription(u.UpgradeType), u.Description)
+		ui.Text(screen, desc, left+16, y+16, ui.TextOptions{Color: hintColor})
+	}
+
+	if s.message != "" {
+		ui.Centered(screen, s.message, cx, world.ScreenHeight-70, ui.TextOptions{Color: color.RGBA{255, 80, 80, 255}})
+	}
+	ui.Centered(screen, g.text.T(i18n.UpgradesHint), cx, world.ScreenHeight-40, ui.TextOptions{Color: hintColor})
+}
+
+// upgradeName は永続的な強化の名前を現在の言語で返します
+func (g *Game) upgradeName(u world.UpgradeParams) string {
+	return g.text.Or(i18n.UpgradeName(u.UpgradeType), cmp.Or(u.Name, string(u.UpgradeType)))
+}
+
+// saveRecords はゲームをまたいで残る記録を保存します
+func (g *Game) saveRecords() {
+	saveRecords(g.saveStorage, g.records)
+}
+
+// saveRecords は records を storage に保存します。storage が nil なら何もしません
+func saveRecords(storage save.Storage, records *save.Data) {
+	if storage == nil {
+		return
+	}
+	if err := save.Save(storage, records); err != nil {
+		log.Printf("failed to save records: %v", err)
+	}
+}
+
+// startRun はタイトル画面から新しいゲームを始めます
+// 買った強化と選んだキャラクター、読み直した定義は新しいゲームから反映します
+// ただし入力の記録中は、記録を始めたときの強化とキャラクターのまま遊びます
+func (g *Game) startRun() {
+	if g.recorder == nil {
+		g.world.SetUpgrades(g.records.UpgradeLevels())
+		g.world.SetCharacter(g.records.Character)
+	}
+	switch {
+	case g.world.GameOver:
+		// 前のゲームの続きから新しいゲームを始めるので、リプレイでも同じ展開になる
+		g.applyInput(world.Input{Restart: true})
+	case g.recorder == nil && g.world.Time() == 0:
+		// まだ始めていない最初のゲームは、強化とキャラクターを反映して作り直す
+		cfg := g.worldConfig
+		cfg.Defs = g.world.NextDefinitions()
+		cfg.Upgrades = g.records.UpgradeLevels()
+		cfg.Character = g.records.Character
+		g.world = world.NewGameWithConfig(cfg)
+	}
+	g.changeScene(playingScene{})
+}
@@ -0,0 +1,232 @@
+package world
+
+import "math"
+
+// 敵の動き方の種類
+type EnemyBehaviorKind string
+
+const (
+	BehaviorChase   EnemyBehaviorKind = "chase"   // プレイヤーにまっすぐ向かう
+	BehaviorShooter EnemyBehaviorKind = "shooter" // 距離を取って弾を撃つ
+	BehaviorCharger EnemyBehaviorKind = "charger" // 予備動作のあと突進する
+)
+
+// enemyBehavior は敵の種類ごとの動き方です
+// 敵ごとの状態は Enemy に持たせるため、実装は状態を持ちません
+type enemyBehavior interface {
+	update(g *Game, e *Enemy)
+}
+
+var enemyBehaviors = map[EnemyBehaviorKind]enemyBehavior{
+	BehaviorChase:   chaseBehavior{},
+	BehaviorShooter: shooterBehavior{},
+	BehaviorCharger: chargerBehavior{},
+}
+
+// valid は種類が定義されているかを返します。空は chase として扱います
+func (k EnemyBehaviorKind) valid() bool {
+	_, ok := enemyBehaviors[k]
+	return k == "" || ok
+}
+
+// ShooterParams は弾を撃つ敵のパラメータです
+type ShooterParams struct {
+	Distance float64 `json:"distance"` // プレイヤーとの間に保つ距離
+	Interval float64 `json:"interval"` // 弾を撃つ間隔（秒）
+	Range    float64 `json:"range"`    // この距離以内にプレイヤーがいると撃つ（弾の射程も同じ）
+	Speed    float64 `json:"speed"`    // 弾の速度（1ティックあたり）
+	Damage   int     `json:"damage"`
+	Count    int     `json:"count"`  // 一度に撃つ弾の数
+	Spread   float64 `json:"spread"` // 弾を広げる角度（度）。360 なら全方位
+}
+
+// ChargeParams は突進する敵のパラメータです
+type ChargeParams struct {
+	Range     float64 `json:"range"`     // この距離以内にプレイヤーがいると突進を始める
+	Telegraph float64 `json:"telegraph"` // 突進の前に止まって構える時間（秒）
+	Speed     float64 `json:"speed"`     // 突進の速度（1ティックあたり）
+	Duration  float64 `json:"duration"`  // 突進を続ける時間（秒）
+	Cooldown  float64 `json:"cooldown"`  // 次の突進までの時間（秒）
+}
+
+// SplitParams は倒されたときに分裂する敵のパラメータです
+type SplitParams struct {
+	Into  EnemyType `json:"into"`
+	Count int       `json:"count"`
+}
+
+// EnemyPhase は残り HP の割合が HP 以下になったときに切り替わる動き方です
+type EnemyPhase struct {
+	HP       float64           `json:"hp"` // 残り HP の割合（0〜1）
+	Behavior EnemyBehaviorKind `json:"behavior"`
+	Speed    float64           `json:"speed"` // 移動速度の倍率（0 なら変えない）
+}
+
+// 突進の状態
+type chargeState int
+
+const (
+	chargeReady     chargeState = iota // 近づいている
+	chargeTelegraph                    // 突進の構え
+	chargeDash                         // 突進中
+	chargeCooldown                     // 突進のあと
+)
+
+// ticks は秒数をティック数に変換します
+func ticks(seconds float64) int {
+	return int(math.Round(seconds * TicksPerSecond))
+}
+
+// behavior は現在のフェーズの動き方の種類を返します
+func (e *Enemy) behavior() EnemyBehaviorKind {
+	kind := e.params.Behavior
+	if e.phase > 0 {
+		kind = e.params.Phases[e.phase-1].Behavior
+	}
+	if kind == "" {
+		return BehaviorChase
+	}
+	return kind
+}
+
+// updatePhase は残り HP に応じてフェーズを進めます
+func (e *Enemy) updatePhase() {
+	for i, ph := range e.params.Phases {
+		if e.phase > i || float64(e.HP) > ph.HP*float64(e.MaxHP) {
+			continue
+		}
+		e.phase = i + 1
+		e.charge = chargeReady
+		e.timer = 0
+		if ph.Speed > 0 {
+			e.Speed = e.params.Speed * ph.Speed
+		}
+	}
+}
+
+// Telegraphing は突進の構えをしているかを返します
+func (e *Enemy) Telegraphing() bool {
+	return e.charge == chargeTelegraph
+}
+
+// updateEnemy は敵を1ティック分動かします
+func (g *Game) updateEnemy(e *Enemy) {
+	if e.HP <= 0 {
+		return
+	}
+	if e.kbX != 0 || e.kbY != 0 {
+		e.X += e.kbX
+		e.Y += e.kbY
+		e.kbX *= knockbackDecay
+		e.kbY *= knockbackDecay
+		if e.kbX*e.kbX+e.kbY*e.kbY < 0.01 {
+			e.kbX, e.kbY = 0, 0
+		}
+		return
+	}
+	e.updatePhase()
+	enemyBehaviors[e.behavior()].update(g, e)
+}
+
+// toPlayer は敵からプレイヤーへの単位ベクトルと距離を返します
+func (g *Game) toPlayer(e *Enemy) (dx, dy, dist float64) {
+	dx = g.Player.X - e.X
+	dy = g.Player.Y - e.Y
+	dist = math.Sqrt(dx*dx + dy*dy)
+	if dist > 0 {
+		dx /= dist
+		dy /= dist
+	}
+	return dx, dy, dist
+}
+
+type chaseBehavior struct{}
+
+func (chaseBehavior) update(g *Game, e *Enemy) {
+	dx, dy, _ := g.toPlayer(e)
+	e.X += dx * e.Speed
+	e.Y += dy * e.Speed
+}
+
+type shooterBehavior struct{}
+
+func (shooterBehavior) update(g *Game, e *Enemy) {
+	p := e.params.Shooter
+	dx, dy, dist := g.toPlayer(e)
+
+	// 保つ距離の前後に少し幅を持たせ、行ったり来たりしないようにする
+	switch {
+	case dist > p.Distance:
+		e.X += dx * e.Speed
+		e.Y += dy * e.Speed
+	case dist < p.Distance*0.8:
+		e.X -= dx * e.Speed
+		e.Y -= dy * e.Speed
+	}
+
+	if e.timer > 0 {
+		e.timer--
+		return
+	}
+	if dist > p.Range {
+		return
+	}
+	e.timer = ticks(p.Interval)
+
+	angle := math.Atan2(dy, dx)
+	spread := p.Spread * math.Pi / 180
+	step := 0.0
+	switch {
+	case p.Spread >= 360:
+		step = spread / float64(p.Count)
+	case p.Count > 1:
+		step = spread / float64(p.Count-1)
+		angle -= spread / 2
+	}
+	for i := 0; i < p.Count; i++ {
+		a := angle + step*float64(i)
+		g.EnemyBullets = append(g.EnemyBullets, &EnemyBullet{
+			X:      e.X,
+			Y:      e.Y,
+			VX:     math.Cos(a) * p.Speed,
+			VY:     math.Sin(a) * p.Speed,
+			Damage: p.Damage,
+			life:   int(p.Range / p.Speed),
+		})
+	}
+}
+
+type chargerBehavior struct{}
+
+func (chargerBehavior) update(g *Game, e *Enemy) {
+	p := e.params.Charge
+	switch e.charge {
+	case chargeReady, chargeCooldown:
+		chaseBehavior{}.update(g, e)
+		if e.timer > 0 {
+			e.timer--
+			return
+		}
+		dx, dy, dist := g.toPlayer(e)
+		if dist <= p.Range {
+			// 構えた時点の方向に突進するので、動けば避けられる
+			e.charge = chargeTelegraph
+			e.timer = ticks(p.Telegraph)
+			e.dirX, e.dirY = dx, dy
+		}
+	case chargeTelegraph:
+		e.timer--
+		if e.timer <= 0 {
+			e.charge = chargeDash
+			e.timer = ticks(p.Duration)
+		}
+	case chargeDash:
+		e.X += e.dirX * p.Speed
+		e.Y += e.dirY * p.Speed
+		e.timer--
+		if e.timer <= 0 {
+			e.charge = chargeCooldown
+			e.timer = ticks(p.Cooldown)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShooterKeepsDistanceAndShoots(t *testing.T) {
+	g := newTestGame()
+	enemy := newEnemy(EnemyParams{
+		Type: "archer", HP: 1, Speed: 2, Size: 16,
+		Behavior: BehaviorShooter,
+		Shooter:  &ShooterParams{Distance: 200, Interval: 1, Range: 300, Speed: 4, Damage: 3, Count: 3, Spread: 30},
+	}, g.Player.X+100, g.Player.Y)
+	g.Enemies = []*Enemy{enemy}
+
+	g.updateEnemy(enemy)
+
+	// 近すぎるので離れる
+	if want := g.Player.X + 102; enemy.X != want {
+		t.Errorf("X = %v, want %v", enemy.X, want)
+	}
+	if len(g.EnemyBullets) != 3 {
+		t.Fatalf("len(EnemyBullets) = %d, want 3", len(g.EnemyBullets))
+	}
+	// 真ん中の弾はプレイヤーに向かう
+	if b := g.EnemyBullets[1]; math.Abs(b.VX+4) > 1e-9 || math.Abs(b.VY) > 1e-9 {
+		t.Errorf("middle bullet velocity = (%v, %v), want (-4, 0)", b.VX, b.VY)
+	}
+
+	// 次に撃つのは1秒後
+	for range TicksPerSecond - 1 {
+		g.updateEnemy(enemy)
+	}
+	if len(g.EnemyBullets) != 3 {
+		t.Errorf("len(EnemyBullets) = %d before the interval, want 3", len(g.EnemyBullets))
+	}
+}
+
+func TestEnemyBulletHitsPlayer(t *testing.T) {
+	g := newTestGame()
+	hp := g.Player.HP
+	g.EnemyBullets = []*EnemyBullet{
+		{X: g.Player.X - 20, Y: g.Player.Y, VX: 5, Damage: 7, life: 10},
+		{X: g.Player.X - 200, Y: g.Player.Y, VX: 5, Damage: 7, life: 1},
+	}
+
+	g.updateEnemyBullets()
+
+	if g.Player.HP != hp-7 {
+		t.Errorf("HP = %d, want %d", g.Player.HP, hp-7)
+	}
+	if len(g.EnemyBullets) != 0 {
+		t.Errorf("len(EnemyBullets) = %d, want 0; hit and expired bullets should be removed", len(g.EnemyBullets))
+	}
+}
+
+func TestEnemyBulletPassesThroughWhileInvulnerable(t *testing.T) {
+	g := newTestGame()
+	g.hurtPlayer(1)
+	hp := g.Player.HP
+	g.EnemyBullets = []*EnemyBullet{
+		{X: g.Player.X - 5, Y: g.Player.Y, VX: 5, Damage: 7, life: 10},
+	}
+
+	g.updateEnemyBullets()
+
+	if g.Player.HP != hp {
+		t.Errorf("HP = %d while invulnerable, want %d", g.Player.HP, hp)
+	}
+	if len(g.EnemyBullets) != 1 {
+		t.Errorf("len(EnemyBullets) = %d, want 1; bullets should pass through while invulnerable", len(g.EnemyBullets))
+	}
+}
+
+func TestChargerTelegraphsThenDashes(t *testing.T) {
+	g := newTestGame()
+	enemy := newEnemy(EnemyParams{
+		Type: "boar", HP: 1, Speed: 1, Size: 16,
+		Behavior: BehaviorCharger,
+		Charge:   &ChargeParams{Range: 150, Telegraph: 0.5, Speed: 10, Duration: 0.25, Cooldown: 1},
+	}, g.Player.X+100, g.Player.Y)
+
+	g.updateEnemy(enemy)
+	if !enemy.Telegraphing() {
+		t.Fatal("Telegraphing() = false with the player in range")
+	}
+
+	// 構えている間は動かない
+	x := enemy.X
+	for range ticks(0.5) {
+		g.updateEnemy(enemy)
+	}
+	if enemy.X != x || enemy.Telegraphing() {
+		t.Fatalf("X, Telegraphing() = %v, %v after telegraph, want %v, false", enemy.X, enemy.Telegraphing(), x)
+	}
+
+	// 構えたときの方向に突進するので、プレイヤーが動いても曲がらない
+	g.Player.Y += 100
+	for range ticks(0.25) {
+		g.updateEnemy(enemy)
+	}
+	if want := x - 10*float64(ticks(0.25)); math.Abs(enemy.X-want) > 1e-9 || enemy.Y != g.Player.Y-100 {
+		t.Errorf("position after dash = (%v, %v), want (%v, %v)", enemy.X, enemy.Y, want, g.Player.Y-100)
+	}
+}
+
+func TestSplitterSpawnsOnDeath(t *testing.T) {
+	g := newTestGame()
+	enemy := placeEnemy(g, "slime", 50, 0)
+
+	g.damageEnemy(enemy, enemy.HP, WeaponMelee)
+
+	small := 0
+	for _, e := range g.Enemies {
+		if e.Type == "slime_small" {
+			small++
+		}
+	}
+	if small != 3 {
+		t.Errorf("spawned %d slime_small, want 3", small)
+	}
+}
+
+func TestBossPhases(t *testing.T) {
+	g := newTestGame()
+	boss := placeEnemy(g, EnemyBoss, 400, 0)
+
+	tests := []struct {
+		hp    int
+		want  EnemyBehaviorKind
+		speed float64
+	}{
+		{boss.MaxHP, BehaviorChase, boss.Speed},
+		{boss.MaxHP / 2, BehaviorShooter, boss.Speed},
+		{boss.MaxHP / 4, BehaviorCharger, boss.Speed * 1.5},
+	}
+	for _, tt := range tests {
+		boss.HP = tt.hp
+		g.updateEnemy(boss)
+		if got := boss.behavior(); got != tt.want {
+			t.Errorf("HP %d: behavior = %q, want %q", tt.hp, got, tt.want)
+		}
+		if boss.Speed != tt.speed {
+			t.Errorf("HP %d: Speed = %v, want %v", tt.hp, boss.Speed, tt.speed)
+		}
+	}
+}
+
+func TestSeparateEnemies(t *testing.T) {
+	g := newTestGame()
+	a := placeEnemy(g, EnemyNormal, 200, 0)
+	b := placeEnemy(g, EnemyNormal, 210, 0)
+
+	for range 20 {
+		g.separateEnemies()
+		g.grid.rebuild(g.Enemies)
+	}
+
+	if dist := math.Abs(b.X - a.X); dist < a.Size-1 {
+		t.Errorf("distance = %v, want about %v", dist, a.Size)
+	}
+}
@@ -0,0 +1,36 @@
+package world
+
+// 敵の弾の当たり判定の大きさ
+const enemyBulletSize = 8
+
+// EnemyBullet は敵が撃った弾です
+type EnemyBullet struct {
+	X, Y   float64
+	VX, VY float64 // 1ティックあたりの移動量
+	Damage int
+	life   int // 残りのティック数
+}
+
+// updateEnemyBullets は敵の弾を動かし、プレイヤーに当たった弾のダメージを与えます
+// 無敵の間は弾がプレイヤーをすり抜け、当たらずに飛び続けます
+func (g *Game) updateEnemyBullets() {
+	bullets := g.EnemyBullets[:0]
+	for _, b := range g.EnemyBullets {
+		b.X += b.VX
+		b.Y += b.VY
+		b.life--
+
+		dx := g.Player.X - b.X
+		dy := g.Player.Y - b.Y
+		r := (playerSize + enemyBulletSize) / 2.0
+		if dx*dx+dy*dy < r*r && !g.Player.Invulnerable() {
+			g.hurtPlayer(b.Damage)
+			continue
+		}
+		if b.life > 0 {
+			bullets = append(bullets, b)
+		}
+	}
+	clear(g.EnemyBullets[len(bullets):])
+	g.EnemyBullets = bullets
+}
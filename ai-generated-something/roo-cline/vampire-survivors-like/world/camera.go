@@ -0,0 +1,27 @@
+package world
+
+// 画面の外に出た敵を取り除くまでの距離
+// 輪の隊列や距離を取る敵が消えないよう、画面の半分程度の余裕を持たせる
+const despawnMargin = ScreenWidth / 2
+
+// Camera は画面に映すワールドの範囲です
+// ワールドには端がなく、カメラはプレイヤーが常に画面の中央に来るように追いかけます
+type Camera struct {
+	X, Y float64 // 画面の左上のワールド座標
+}
+
+// cameraAt は (x, y) を画面の中央に映すカメラを返します
+func cameraAt(x, y float64) Camera {
+	return Camera{X: x - ScreenWidth/2, Y: y - ScreenHeight/2}
+}
+
+// Contains は (x, y) が画面を margin だけ広げた範囲に入っているかを返します
+func (c Camera) Contains(x, y, margin float64) bool {
+	return x >= c.X-margin && x <= c.X+ScreenWidth+margin &&
+		y >= c.Y-margin && y <= c.Y+ScreenHeight+margin
+}
+
+// Camera は現在のカメラを返します
+func (g *Game) Camera() Camera {
+	return g.camera
+}
@@ -0,0 +1,50 @@
+package world
+
+import "testing"
+
+func TestCameraFollowsPlayer(t *testing.T) {
+	g := newTestGame()
+	for range 100 {
+		g.Step(Input{MoveX: 1, MoveY: 1})
+	}
+
+	cam := g.Camera()
+	if cam.X+ScreenWidth/2 != g.Player.X || cam.Y+ScreenHeight/2 != g.Player.Y {
+		t.Errorf("camera center = (%v, %v), want player (%v, %v)",
+			cam.X+ScreenWidth/2, cam.Y+ScreenHeight/2, g.Player.X, g.Player.Y)
+	}
+
+	// 敵はカメラに映る範囲のすぐ外に出現する
+	g.Enemies = nil
+	g.spawnEnemy()
+	e := g.Enemies[0]
+	if cam.Contains(e.X, e.Y, 0) || !cam.Contains(e.X, e.Y, 50) {
+		t.Errorf("enemy spawned at (%v, %v), want just outside the camera %+v", e.X, e.Y, cam)
+	}
+}
+
+func TestFarEnemiesDespawn(t *testing.T) {
+	g := newTestGame()
+	near := placeEnemy(g, EnemyNormal, ScreenWidth/2+despawnMargin-100, 0)
+	far := placeEnemy(g, EnemyNormal, ScreenWidth/2+despawnMargin+100, 0)
+	boss := placeEnemy(g, EnemyBoss, ScreenWidth/2+despawnMargin+100, 0)
+
+	g.Step(Input{})
+
+	alive := make(map[*Enemy]bool)
+	for _, e := range g.Enemies {
+		alive[e] = true
+	}
+	if !alive[near] {
+		t.Error("enemy near the screen was removed")
+	}
+	if alive[far] {
+		t.Error("enemy far from the screen was not removed")
+	}
+	if !alive[boss] {
+		t.Error("boss far from the screen was removed")
+	}
+	if got := g.Stats().Kills[EnemyNormal]; got != 0 {
+		t.Errorf("Kills[normal] = %d, want 0; despawned enemies are not kills", got)
+	}
+}
@@ -0,0 +1,88 @@
+package world
+
+// CharacterType はキャラクターの種類です
+type CharacterType string
+
+// CharacterParams は選べるキャラクターの定義です
+// 最初に持っている武器とステータス、キャラクターだけが持つパッシブを決めます
+type CharacterParams struct {
+	CharacterType CharacterType    `json:"id"`
+	Name          string           `json:"name"`
+	Description   string           `json:"description"`
+	Weapon        WeaponType       `json:"weapon"` // 空なら player.weapon
+	HP            int              `json:"hp"`     // 0 なら player.hp
+	Speed         float64          `json:"speed"`  // 0 なら player.speed
+	Color         Color            `json:"color"`  // 表示色
+	Passive       CharacterPassive `json:"passive"`
+	Unlock        *UnlockCondition `json:"unlock"` // nil なら最初から選べる
+}
+
+// CharacterPassive はキャラクターだけが持つパッシブです。外したり強化したりはできません
+type CharacterPassive struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Modifiers   []Modifier `json:"modifiers"` // 常にかかる補正
+	PerLevel    []Modifier `json:"per_level"` // プレイヤーのレベルが1上がるごとに増える補正
+}
+
+// UnlockKind はキャラクターを選べるようになる条件の種類です
+type UnlockKind string
+
+const (
+	UnlockSurvival UnlockKind = "survival" // 1回のプレイで value 秒生き延びる
+	UnlockScore    UnlockKind = "score"    // 1回のプレイでスコア value を取る
+	UnlockLevel    UnlockKind = "level"    // 1回のプレイでレベル value に達する
+	UnlockKills    UnlockKind = "kills"    // enemy を累計 value 体倒す（enemy が空ならすべての敵）
+	UnlockRuns     UnlockKind = "runs"     // value 回遊ぶ
+)
+
+func (k UnlockKind) valid() bool {
+	switch k {
+	case UnlockSurvival, UnlockScore, UnlockLevel, UnlockKills, UnlockRuns:
+		return true
+	}
+	return false
+}
+
+// UnlockCondition はキャラクターを選べるようになる条件です
+type UnlockCondition struct {
+	Kind  UnlockKind `json:"kind"`
+	Value float64    `json:"value"`
+	Enemy EnemyType  `json:"enemy,omitempty"` // kills のときの敵の種類
+}
+
+// Base は p の最初の武器とステータスをキャラクターのものに置き換えて返します
+func (c CharacterParams) Base(p PlayerParams) PlayerParams {
+	if c.Weapon != "" {
+		p.Weapon = c.Weapon
+	}
+	if c.HP > 0 {
+		p.HP = c.HP
+	}
+	if c.Speed > 0 {
+		p.Speed = c.Speed
+	}
+	return p
+}
+
+// character は id のキャラクターを返します
+// 見つからなければ最初のキャラクターを、キャラクターが定義されていなければ player の設定のままのキャラクターを返します
+func (d *Definitions) character(id CharacterType) CharacterParams {
+	if c, ok := d.Character(id); ok {
+		return c
+	}
+	if len(d.Characters) > 0 {
+		return d.Characters[0]
+	}
+	return CharacterParams{}
+}
+
+// Character は今のゲームのキャラクターを返します
+func (g *Game) Character() CharacterParams {
+	return g.Player.character
+}
+
+// SetCharacter は使うキャラクターを設定します。次のリスタートから反映されます
+func (g *Game) SetCharacter(id CharacterType) {
+	g.nextCharacter = id
+}
@@ -0,0 +1,60 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCharacterStartingLoadout(t *testing.T) {
+	defs := DefaultDefinitions()
+	mage, _ := defs.Character("mage")
+	g := NewGameWithConfig(Config{Seed: 1, Defs: defs, Character: "mage"})
+
+	if g.Character().CharacterType != "mage" {
+		t.Fatalf("Character() = %q, want mage", g.Character().CharacterType)
+	}
+	if len(g.Player.Weapons) != 1 || g.Player.Weapons[0].Params.WeaponType != mage.Weapon {
+		t.Errorf("starting weapons = %v, want only %q", g.Player.Weapons, mage.Weapon)
+	}
+	if g.Player.MaxHP != mage.HP {
+		t.Errorf("MaxHP = %d, want %d", g.Player.MaxHP, mage.HP)
+	}
+
+	// 定義にないキャラクターは最初のキャラクターになる
+	if got := NewGameWithConfig(Config{Seed: 1, Defs: defs, Character: "unknown"}).Character(); got.CharacterType != defs.Characters[0].CharacterType {
+		t.Errorf("Character() = %q for an unknown id, want %q", got.CharacterType, defs.Characters[0].CharacterType)
+	}
+}
+
+func TestCharacterPassivePerLevel(t *testing.T) {
+	g := NewGameWithConfig(Config{Seed: 1, Character: "mage"})
+	mage := g.Character()
+	if g.Player.stats[StatMight] != 1 {
+		t.Fatalf("might = %v at level 1, want 1", g.Player.stats[StatMight])
+	}
+
+	g.Pickups = []*Pickup{{X: g.Player.X, Y: g.Player.Y, Kind: PickupExp, Value: g.Player.ExpToNextLevel}}
+	g.Step(Input{})
+	if g.Player.Level != 2 {
+		t.Fatalf("Level = %d, want 2", g.Player.Level)
+	}
+	want := 1 + mage.Passive.PerLevel[0].Percent/100
+	if got := g.Player.stats[StatMight]; math.Abs(got-want) > 1e-9 {
+		t.Errorf("might = %v at level 2, want %v", got, want)
+	}
+}
+
+func TestSetCharacterAppliesOnRestart(t *testing.T) {
+	g := newTestGame()
+	first := g.Character().CharacterType
+	g.SetCharacter("trickster")
+	if g.Character().CharacterType != first {
+		t.Fatal("SetCharacter changed the current run")
+	}
+
+	g.GameOver = true
+	g.Step(Input{Restart: true})
+	if got := g.Character().CharacterType; got != "trickster" {
+		t.Errorf("Character() = %q after restart, want trickster", got)
+	}
+}
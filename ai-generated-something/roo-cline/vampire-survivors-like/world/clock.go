@@ -0,0 +1,36 @@
+package world
+
+// TicksPerSecond は1秒あたりの Step 回数です
+const TicksPerSecond = 60
+
+// Clock はゲーム内の時間を管理するインターフェースです
+// 壁時計ではなくシミュレーション上の時間を返すため、
+// フレームレートに依存せず同じ入力から同じ結果が得られます
+type Clock interface {
+	// Now はゲーム開始からの経過時間（秒）を返します
+	Now() float64
+	// Tick は時間を1ティック分進めます
+	Tick()
+}
+
+// FixedClock は Tick ごとに固定の時間だけ進むクロックです
+type FixedClock struct {
+	ticks    int64
+	tickSize float64 // 1ティックあたりの秒数
+}
+
+// NewFixedClock は1ティックあたり tickSize 秒進むクロックを作成します
+func NewFixedClock(tickSize float64) *FixedClock {
+	return &FixedClock{tickSize: tickSize}
+}
+
+// Now はゲーム開始からの経過時間（秒）を返します
+// 浮動小数点の誤差が蓄積しないようティック数から毎回計算します
+func (c *FixedClock) Now() float64 {
+	return float64(c.ticks) * c.tickSize
+}
+
+// Tick は時間を1ティック分進めます
+func (c *FixedClock) Tick() {
+	c.ticks++
+}
@@ -0,0 +1,53 @@
+package world
+
+import "testing"
+
+func TestFixedClock(t *testing.T) {
+	c := NewFixedClock(1.0 / TicksPerSecond)
+	if c.Now() != 0 {
+		t.Fatalf("Now() = %v before the first tick, want 0", c.Now())
+	}
+	for i := 1; i <= 10*60*TicksPerSecond; i++ {
+		c.Tick()
+		// ティック数から計算するので、何ティック進めても誤差がたまらない
+		if want := float64(i) * (1.0 / TicksPerSecond); c.Now() != want {
+			t.Fatalf("Now() = %v after %d ticks, want %v", c.Now(), i, want)
+		}
+	}
+	if c.Now() != 600 {
+		t.Errorf("Now() = %v after 10 minutes of ticks, want 600", c.Now())
+	}
+}
+
+func TestClockStopsWhileChoosingSkill(t *testing.T) {
+	clock := NewFixedClock(1.0 / TicksPerSecond)
+	g := NewGameWithConfig(Config{Seed: 1, Clock: clock})
+	g.Step(Input{})
+	if g.Time() != 1.0/TicksPerSecond {
+		t.Fatalf("Time() = %v after one Step, want %v", g.Time(), 1.0/TicksPerSecond)
+	}
+
+	g.levelUp(1)
+	if !g.ChoosingSkill {
+		t.Fatal("ChoosingSkill = false after a level up")
+	}
+	enemy := placeEnemy(g, EnemyNormal, 100, 0)
+	x, px := enemy.X, g.Player.X
+	now := g.Time()
+	for range TicksPerSecond {
+		g.Step(Input{MoveX: 1})
+	}
+	if g.Time() != now || clock.Now() != now {
+		t.Errorf("Time() = %v after Steps while choosing a skill, want %v", g.Time(), now)
+	}
+	if enemy.X != x || g.Player.X != px {
+		t.Errorf("enemy and player X = %v, %v while choosing a skill, want %v, %v", enemy.X, g.Player.X, x, px)
+	}
+
+	// 選び終えたら次の Step から1ティックずつ進む
+	g.Step(Input{Choice: 1})
+	g.Step(Input{})
+	if want := now + 1.0/TicksPerSecond; g.Time() != want {
+		t.Errorf("Time() = %v after choosing, want %v", g.Time(), want)
+	}
+}
@@ -0,0 +1,603 @@
+package world
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 組み込みの定義ファイル
+//
+//go:embed defs.json
+var defaultDefsJSON []byte
+
+// Definitions は武器・敵・スキルなどのゲームバランスに関わる定義です
+// コードを変更せずに武器や敵を追加・調整できるよう、JSON から読み込みます
+type Definitions struct {
+	Player     PlayerParams      `json:"player"`
+	Weapons    []WeaponParams    `json:"weapons"`
+	Enemies    []EnemyParams     `json:"enemies"`
+	Skills     []SkillOption     `json:"skills"`
+	LevelUp    LevelUpParams     `json:"level_up"`
+	Passives   []PassiveParams   `json:"passives"`
+	Evolutions []Evolution       `json:"evolutions"`
+	Stages     []StageParams     `json:"stages"` // 最初のステージが既定
+	Pickups    PickupParams      `json:"pickups"`
+	Upgrades   []UpgradeParams   `json:"upgrades"`   // コインで買う永続的な強化
+	Characters []CharacterParams `json:"characters"` // 選べるキャラクター。最初のキャラクターが既定
+	Coins      CoinParams        `json:"coins"`
+
+	weapons    map[WeaponType]*WeaponParams
+	enemies    map[EnemyType]*EnemyParams
+	passives   map[PassiveType]*PassiveParams
+	stages     map[string]*StageParams
+	upgrades   map[UpgradeType]*UpgradeParams
+	characters map[CharacterType]*CharacterParams
+}
+
+// PlayerParams はプレイヤーの初期パラメータです
+type PlayerParams struct {
+	Speed        float64    `json:"speed"`
+	HP           int        `json:"hp"`
+	Weapon       WeaponType `json:"weapon"`        // 最初から持っている武器
+	PickupRadius float64    `json:"pickup_radius"` // アイテムを引き寄せる範囲
+	ExpCurve     ExpCurve   `json:"exp_curve"`
+
+	Armor           float64 `json:"armor"`           // 受けるダメージの軽減量
+	Invulnerability float64 `json:"invulnerability"` // ダメージを受けたあと無敵になる時間（秒）
+}
+
+// Color は定義ファイルで "#rrggbb" または "#rrggbbaa" と書く色です
+type Color color.RGBA
+
+// UnmarshalJSON は "#rrggbb" 形式の文字列を色に変換します
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || (len(hex) != 6 && len(hex) != 8) {
+		return fmt.Errorf("invalid color %q: want #rrggbb or #rrggbbaa", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return fmt.Errorf("invalid color %q: %v", s, err)
+	}
+	*c = Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}
+	return nil
+}
+
+var defaultDefs = mustParseDefinitions(defaultDefsJSON)
+
+// DefaultDefinitions は組み込みの定義を返します
+func DefaultDefinitions() *Definitions {
+	return defaultDefs
+}
+
+func mustParseDefinitions(data []byte) *Definitions {
+	defs, err := ParseDefinitions(data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded defs.json: %v", err))
+	}
+	return defs
+}
+
+// LoadDefinitions はファイルから定義を読み込みます
+func LoadDefinitions(path string) (*Definitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := ParseDefinitions(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return defs, nil
+}
+
+// ParseDefinitions は JSON から定義を読み込み、内容を検証します
+func ParseDefinitions(data []byte) (*Definitions, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// 項目名の打ち間違いに気付けるよう、未知の項目はエラーにする
+	dec.DisallowUnknownFields()
+
+	var defs Definitions
+	if err := dec.Decode(&defs); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return nil, fmt.Errorf("line %d: %w", lineOf(data, syntaxErr.Offset), err)
+		case errors.As(err, &typeErr):
+			return nil, fmt.Errorf("line %d: %s: want %s, got %s", lineOf(data, typeErr.Offset), typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		return nil, err
+	}
+	if err := defs.Validate(); err != nil {
+		return nil, err
+	}
+	return &defs, nil
+}
+
+// lineOf は data の offset バイト目が何行目かを返します
+func lineOf(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// Validate は定義の内容を検証し、見つかったすべての問題を返します
+func (d *Definitions) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	d.weapons = make(map[WeaponType]*WeaponParams, len(d.Weapons))
+	for i := range d.Weapons {
+		w := &d.Weapons[i]
+		where := fmt.Sprintf("weapons[%d] %q", i, w.WeaponType)
+		switch {
+		case w.WeaponType == "":
+			fail("weapons[%d]: id is required", i)
+		case d.weapons[w.WeaponType] != nil:
+			fail("%s: duplicate id", where)
+		default:
+			d.weapons[w.WeaponType] = w
+		}
+		if !w.Behavior.valid() {
+			fail("%s: unknown behavior %q (melee, ranged, aura, spiral)", where, w.Behavior)
+		}
+		if w.AttackInterval <= 0 {
+			fail("%s: attack_interval must be positive", where)
+		}
+		if w.AttackRange <= 0 {
+			fail("%s: attack_range must be positive", where)
+		}
+		if w.AttackDamage < 0 {
+			fail("%s: attack_damage must not be negative", where)
+		}
+		if w.Behavior.shoots() && w.ProjectileSpeed <= 0 {
+			fail("%s: projectile_speed must be positive for %s weapons", where, w.Behavior)
+		}
+		if w.Pierce < 0 {
+			fail("%s: pierce must not be negative", where)
+		}
+		if w.HitCooldown < 0 {
+			fail("%s: hit_cooldown must not be negative", where)
+		}
+		if w.Name == "" {
+			fail("%s: name is required", where)
+		}
+		if w.ProjectileCount < 0 {
+			fail("%s: projectile_count must not be negative", where)
+		}
+		// すべてのレベルを上げた後も攻撃間隔が正でなければならない
+		maxed := *w
+		for j, l := range w.Levels {
+			maxed.apply(l)
+			if l.Description == "" {
+				fail("%s: levels[%d]: description is required", where, j)
+			}
+			if maxed.AttackInterval <= 0 {
+				fail("%s: levels[%d]: attack_interval becomes %v", where, j, maxed.AttackInterval)
+			}
+			if maxed.AttackRange <= 0 {
+				fail("%s: levels[%d]: attack_range becomes %v", where, j, maxed.AttackRange)
+			}
+		}
+	}
+
+	d.enemies = make(map[EnemyType]*EnemyParams, len(d.Enemies))
+	for i := range d.Enemies {
+		e := &d.Enemies[i]
+		where := fmt.Sprintf("enemies[%d] %q", i, e.Type)
+		switch {
+		case e.Type == "":
+			fail("enemies[%d]: id is required", i)
+		case d.enemies[e.Type] != nil:
+			fail("%s: duplicate id", where)
+		default:
+			d.enemies[e.Type] = e
+		}
+		if e.HP <= 0 {
+			fail("%s: hp must be positive", where)
+		}
+		if e.Speed < 0 {
+			fail("%s: speed must not be negative", where)
+		}
+		if e.Size <= 0 {
+			fail("%s: size must be positive", where)
+		}
+		if e.Damage < 0 {
+			fail("%s: damage must not be negative", where)
+		}
+		if e.KnockbackResist < 0 || e.KnockbackResist > 1 {
+			fail("%s: knockback_resist must be between 0 and 1", where)
+		}
+	}
+	// 分裂先や動き方の参照はすべての敵を読み込んでから検証する
+	for i := range d.Enemies {
+		e := &d.Enemies[i]
+		where := fmt.Sprintf("enemies[%d] %q", i, e.Type)
+		kinds := []EnemyBehaviorKind{e.Behavior}
+		for j, ph := range e.Phases {
+			if ph.HP <= 0 || ph.HP >= 1 {
+				fail("%s: phases[%d]: hp must be between 0 and 1", where, j)
+			}
+			if j > 0 && ph.HP >= e.Phases[j-1].HP {
+				fail("%s: phases[%d]: hp must be less than the previous phase", where, j)
+			}
+			kinds = append(kinds, ph.Behavior)
+		}
+		for _, kind := range kinds {
+			switch {
+			case !kind.valid():
+				fail("%s: unknown behavior %q (chase, shooter, charger)", where, kind)
+			case kind == BehaviorShooter && e.Shooter == nil:
+				fail("%s: behavior shooter needs shooter parameters", where)
+			case kind == BehaviorCharger && e.Charge == nil:
+				fail("%s: behavior charger needs charge parameters", where)
+			}
+		}
+		if p := e.Shooter; p != nil && (p.Interval <= 0 || p.Range <= 0 || p.Speed <= 0 || p.Count <= 0) {
+			fail("%s: shooter: interval, range, speed and count must be positive", where)
+		}
+		if p := e.Charge; p != nil && (p.Range <= 0 || p.Speed <= 0 || p.Duration <= 0) {
+			fail("%s: charge: range, speed and duration must be positive", where)
+		}
+		if split := e.Split; split != nil {
+			into := d.enemies[split.Into]
+			switch {
+			case into == nil:
+				fail("%s: split: unknown enemy %q", where, split.Into)
+			case into.Split != nil:
+				// 分裂が終わらなくなるのを防ぐ
+				fail("%s: split: enemy %q must not split again", where, split.Into)
+			}
+			if split.Count <= 0 {
+				fail("%s: split: count must be positive", where)
+			}
+		}
+	}
+
+	if d.Player.HP <= 0 {
+		fail("player: hp must be positive")
+	}
+	if d.Player.Speed <= 0 {
+		fail("player: speed must be positive")
+	}
+	if d.weapons[d.Player.Weapon] == nil {
+		fail("player: unknown weapon %q", d.Player.Weapon)
+	}
+	if d.Player.Armor < 0 || d.Player.Invulnerability < 0 {
+		fail("player: armor and invulnerability must not be negative")
+	}
+	if d.Player.PickupRadius < 0 {
+		fail("player: pickup_radius must not be negative")
+	}
+	if d.Player.ExpCurve.Base <= 0 {
+		fail("player.exp_curve: base must be positive")
+	}
+	if d.Player.ExpCurve.Growth < 0 {
+		fail("player.exp_curve: growth must not be negative")
+	}
+	for i, exp := range d.Player.ExpCurve.Table {
+		if exp <= 0 {
+			fail("player.exp_curve.table[%d]: must be positive", i)
+		}
+	}
+
+	if len(d.Skills) == 0 {
+		fail("skills: at least one skill is required")
+	}
+	for i, s := range d.Skills {
+		if !s.Type.valid() {
+			fail("skills[%d]: unknown type %q (new_weapon, weapon_upgrade, passive)", i, s.Type)
+		}
+		if s.Description == "" {
+			fail("skills[%d]: description is required", i)
+		}
+		if s.Weight <= 0 {
+			fail("skills[%d]: weight must be positive", i)
+		}
+	}
+	if d.LevelUp.Rerolls < 0 || d.LevelUp.Skips < 0 || d.LevelUp.Banishes < 0 {
+		fail("level_up: rerolls, skips and banishes must not be negative")
+	}
+
+	d.passives = make(map[PassiveType]*PassiveParams, len(d.Passives))
+	for i := range d.Passives {
+		p := &d.Passives[i]
+		where := fmt.Sprintf("passives[%d] %q", i, p.PassiveType)
+		switch {
+		case p.PassiveType == "":
+			fail("passives[%d]: id is required", i)
+		case d.passives[p.PassiveType] != nil:
+			fail("%s: duplicate id", where)
+		default:
+			d.passives[p.PassiveType] = p
+		}
+		if p.Name == "" {
+			fail("%s: name is required", where)
+		}
+		for j, m := range p.Modifiers {
+			if !m.Stat.valid() {
+				fail("%s: modifiers[%d]: unknown stat %q", where, j, m.Stat)
+			}
+		}
+		if p.MaxLevel <= 0 {
+			fail("%s: max_level must be positive", where)
+		}
+	}
+
+	for i, evo := range d.Evolutions {
+		where := fmt.Sprintf("evolutions[%d]", i)
+		if d.weapons[evo.Weapon] == nil {
+			fail("%s: unknown weapon %q", where, evo.Weapon)
+		}
+		if d.passives[evo.Passive] == nil {
+			fail("%s: unknown passive %q", where, evo.Passive)
+		}
+		if into := d.weapons[evo.Into]; into == nil {
+			fail("%s: unknown weapon %q", where, evo.Into)
+		} else if !into.Evolution {
+			fail("%s: weapon %q must be marked as evolution", where, evo.Into)
+		}
+	}
+
+	if len(d.Stages) == 0 {
+		fail("stages: at least one stage is required")
+	}
+	d.stages = make(map[string]*StageParams, len(d.Stages))
+	for i := range d.Stages {
+		st := &d.Stages[i]
+		where := fmt.Sprintf("stages[%d] %q", i, st.ID)
+		switch {
+		case st.ID == "":
+			fail("stages[%d]: id is required", i)
+		case d.stages[st.ID] != nil:
+			fail("%s: duplicate id", where)
+		default:
+			d.stages[st.ID] = st
+		}
+		if st.MaxEnemies <= 0 {
+			fail("%s: max_enemies must be positive", where)
+		}
+
+		if len(st.Rate) == 0 || st.Rate[0].Time != 0 {
+			fail("%s: the first rate point must start from 0", where)
+		}
+		for j, r := range st.Rate {
+			if j > 0 && r.Time <= st.Rate[j-1].Time {
+				fail("%s: rate[%d]: time must be greater than the previous point", where, j)
+			}
+			if r.PerSecond < 0 {
+				fail("%s: rate[%d]: per_second must not be negative", where, j)
+			}
+		}
+
+		if len(st.Bands) == 0 || st.Bands[0].From != 0 {
+			fail("%s: the first band must start from 0", where)
+		}
+		for j, band := range st.Bands {
+			if j > 0 && band.From <= st.Bands[j-1].From {
+				fail("%s: bands[%d]: from must be greater than the previous band", where, j)
+			}
+			total := 0
+			for k, sw := range band.Enemies {
+				if d.enemies[sw.Type] == nil {
+					fail("%s: bands[%d].enemies[%d]: unknown enemy %q", where, j, k, sw.Type)
+				}
+				if sw.Weight <= 0 {
+					fail("%s: bands[%d].enemies[%d]: weight must be positive", where, j, k)
+				}
+				total += sw.Weight
+			}
+			if total <= 0 {
+				fail("%s: bands[%d]: no enemies", where, j)
+			}
+		}
+
+		for j, ev := range st.Events {
+			at := fmt.Sprintf("%s: events[%d]", where, j)
+			if !ev.Formation.valid() {
+				fail("%s: unknown formation %q (ring, swarm, boss)", at, ev.Formation)
+			}
+			if d.enemies[ev.Enemy] == nil {
+				fail("%s: unknown enemy %q", at, ev.Enemy)
+			}
+			if ev.Time < 0 || ev.Repeat < 0 || ev.Warning < 0 {
+				fail("%s: time, repeat and warning must not be negative", at)
+			}
+			if ev.Count <= 0 {
+				fail("%s: count must be positive", at)
+			}
+			if ev.Formation == FormationRing && ev.Radius <= 0 {
+				fail("%s: ring needs a positive radius", at)
+			}
+			if ev.Formation == FormationSwarm && ev.Spread <= 0 {
+				fail("%s: swarm needs a positive spread", at)
+			}
+		}
+	}
+
+	if d.Pickups.AttractSpeed <= 0 {
+		fail("pickups: attract_speed must be positive")
+	}
+	if d.Pickups.CollectRadius <= 0 {
+		fail("pickups: collect_radius must be positive")
+	}
+	if len(d.Pickups.Gems) == 0 {
+		fail("pickups: at least one gem tier is required")
+	}
+	for i, tier := range d.Pickups.Gems {
+		if i > 0 && tier.MinExp <= d.Pickups.Gems[i-1].MinExp {
+			fail("pickups.gems[%d]: min_exp must be greater than the previous tier", i)
+		}
+	}
+	if c := d.Pickups.Heal.Chance; c < 0 || c > 1 {
+		fail("pickups.heal: chance must be between 0 and 1")
+	}
+	d.upgrades = make(map[UpgradeType]*UpgradeParams, len(d.Upgrades))
+	for i := range d.Upgrades {
+		u := &d.Upgrades[i]
+		where := fmt.Sprintf("upgrades[%d] %q", i, u.UpgradeType)
+		switch {
+		case u.UpgradeType == "":
+			fail("upgrades[%d]: id is required", i)
+		case d.upgrades[u.UpgradeType] != nil:
+			fail("%s: duplicate id", where)
+		default:
+			d.upgrades[u.UpgradeType] = u
+		}
+		if u.Name == "" {
+			fail("%s: name is required", where)
+		}
+		for j, m := range u.Modifiers {
+			if !m.Stat.valid() {
+				fail("%s: modifiers[%d]: unknown stat %q", where, j, m.Stat)
+			}
+		}
+		if u.MaxLevel <= 0 {
+			fail("%s: max_level must be positive", where)
+		}
+		if u.Cost <= 0 {
+			fail("%s: cost must be positive", where)
+		}
+	}
+	d.characters = make(map[CharacterType]*CharacterParams, len(d.Characters))
+	unlocked := false
+	for i := range d.Characters {
+		c := &d.Characters[i]
+		where := fmt.Sprintf("characters[%d] %q", i, c.CharacterType)
+		switch {
+		case c.CharacterType == "":
+			fail("characters[%d]: id is required", i)
+		case d.characters[c.CharacterType] != nil:
+			fail("%s: duplicate id", where)
+		default:
+			d.characters[c.CharacterType] = c
+		}
+		if c.Name == "" {
+			fail("%s: name is required", where)
+		}
+		if c.Weapon != "" && d.weapons[c.Weapon] == nil {
+			fail("%s: unknown weapon %q", where, c.Weapon)
+		}
+		if c.HP < 0 || c.Speed < 0 {
+			fail("%s: hp and speed must not be negative", where)
+		}
+		for j, m := range slices.Concat(c.Passive.Modifiers, c.Passive.PerLevel) {
+			if !m.Stat.valid() {
+				fail("%s: passive: modifiers[%d]: unknown stat %q", where, j, m.Stat)
+			}
+		}
+		if u := c.Unlock; u == nil {
+			unlocked = true
+		} else {
+			if !u.Kind.valid() {
+				fail("%s: unlock: unknown kind %q (survival, score, level, kills, runs)", where, u.Kind)
+			}
+			if u.Value <= 0 {
+				fail("%s: unlock: value must be positive", where)
+			}
+			if u.Enemy != "" && d.enemies[u.Enemy] == nil {
+				fail("%s: unlock: unknown enemy %q", where, u.Enemy)
+			}
+		}
+	}
+	if len(d.Characters) > 0 && !unlocked {
+		fail("characters: at least one character must have no unlock condition")
+	}
+
+	if d.Coins.PerScore < 0 || d.Coins.PerMinute < 0 {
+		fail("coins: per_score and per_minute must not be negative")
+	}
+
+	if c := d.Pickups.Magnet.Chance; c < 0 || c > 1 {
+		fail("pickups.magnet: chance must be between 0 and 1")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Weapon は id の武器の定義を返します
+func (d *Definitions) Weapon(id WeaponType) (WeaponParams, bool) {
+	w, ok := d.weapons[id]
+	if !ok {
+		return WeaponParams{}, false
+	}
+	return *w, true
+}
+
+// Passive は id のパッシブアイテムの定義を返します
+func (d *Definitions) Passive(id PassiveType) (PassiveParams, bool) {
+	p, ok := d.passives[id]
+	if !ok {
+		return PassiveParams{}, false
+	}
+	return *p, true
+}
+
+// Upgrade は id の永続的な強化の定義を返します
+func (d *Definitions) Upgrade(id UpgradeType) (UpgradeParams, bool) {
+	u, ok := d.upgrades[id]
+	if !ok {
+		return UpgradeParams{}, false
+	}
+	return *u, true
+}
+
+// Character は id のキャラクターの定義を返します
+func (d *Definitions) Character(id CharacterType) (CharacterParams, bool) {
+	c, ok := d.characters[id]
+	if !ok {
+		return CharacterParams{}, false
+	}
+	return *c, true
+}
+
+// Stage は id のステージの定義を返します
+func (d *Definitions) Stage(id string) (StageParams, bool) {
+	s, ok := d.stages[id]
+	if !ok {
+		return StageParams{}, false
+	}
+	return *s, true
+}
+
+// Enemy は id の敵の定義を返します
+func (d *Definitions) Enemy(id EnemyType) (EnemyParams, bool) {
+	e, ok := d.enemies[id]
+	if !ok {
+		return EnemyParams{}, false
+	}
+	return *e, true
+}
+
+// Hash は定義の内容から計算した値を返します
+// 同じ内容の定義は、読み込んだファイルの書き方によらず同じ値になります
+func (d *Definitions) Hash() uint64 {
+	data, err := json.Marshal(d)
+	if err != nil {
+		// 読み込めた定義は必ず JSON に戻せる
+		panic(fmt.Sprintf("marshal definitions: %v", err))
+	}
+	sum := sha256.Sum256(data)
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,151 @@
+package world
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultDefinitions(t *testing.T) {
+	defs := DefaultDefinitions()
+	for _, id := range []WeaponType{WeaponMelee, WeaponRanged, WeaponAura, WeaponSpiral} {
+		if _, ok := defs.Weapon(id); !ok {
+			t.Errorf("weapon %q is not defined", id)
+		}
+	}
+	for _, id := range []EnemyType{EnemyNormal, EnemyFast, EnemyTank, EnemyBoss} {
+		if _, ok := defs.Enemy(id); !ok {
+			t.Errorf("enemy %q is not defined", id)
+		}
+	}
+}
+
+func TestParseDefinitionsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []string
+	}{
+		{
+			name: "syntax error",
+			json: "{\n\"player\": {\n\"speed\": 4,,\n}}",
+			want: []string{"line 3"},
+		},
+		{
+			name: "unknown field",
+			json: `{"player": {"sped": 4}}`,
+			want: []string{`unknown field "sped"`},
+		},
+		{
+			name: "type mismatch",
+			json: "{\n\"player\": {\"hp\": \"100\"}}",
+			want: []string{"line 2", "player.hp"},
+		},
+		{
+			name: "invalid values",
+			json: `{
+				"player": {"speed": 4, "hp": 100, "weapon": "sword"},
+				"weapons": [
+					{"id": "wand", "behavior": "laser", "attack_interval": 0, "attack_range": 10, "color": "#ffffff"},
+					{"id": "wand", "behavior": "ranged", "attack_interval": 1, "attack_range": 10, "color": "#ffffff"}
+				],
+				"enemies": [
+					{"id": "bat", "hp": 1, "speed": 1, "size": 8, "color": "#ffffff"},
+					{"id": "imp", "hp": 1, "speed": 1, "size": 8, "color": "#ffffff", "behavior": "shooter",
+						"split": {"into": "golem", "count": 2}}
+				],
+				"skills": [{"type": "hp_up", "description": "HP", "weight": 0}],
+				"level_up": {"rerolls": -1},
+				"passives": [{"id": "ring", "name": "指輪", "modifiers": [{"stat": "charm", "flat": 1}], "max_level": 1}],
+				"evolutions": [{"weapon": "wand", "passive": "ring", "into": "wand"}],
+				"stages": [{"id": "cave", "max_enemies": 10, "rate": [{"time": 0, "per_second": 1}],
+					"bands": [{"from": 0, "enemies": [{"id": "ghost", "weight": 1}]}],
+					"events": [{"time": 10, "formation": "ring", "enemy": "bat", "count": 8}]}],
+				"upgrades": [{"id": "vigor", "name": "活力", "modifiers": [{"stat": "max_hp", "flat": 10}], "max_level": 3, "cost": 0}],
+				"characters": [{"id": "rogue", "name": "盗賊", "weapon": "dagger", "color": "#ffffff",
+					"passive": {"name": "影", "per_level": [{"stat": "stealth", "flat": 1}]},
+					"unlock": {"kind": "coins", "value": 10}}]
+			}`,
+			want: []string{
+				`weapons[0] "wand": unknown behavior "laser"`,
+				`weapons[0] "wand": attack_interval must be positive`,
+				`weapons[1] "wand": duplicate id`,
+				`weapons[1] "wand": projectile_speed must be positive`,
+				`player: unknown weapon "sword"`,
+				`skills[0]: unknown type "hp_up"`,
+				`skills[0]: weight must be positive`,
+				"level_up: rerolls, skips and banishes must not be negative",
+				`passives[0] "ring": modifiers[0]: unknown stat "charm"`,
+				`evolutions[0]: weapon "wand" must be marked as evolution`,
+				`stages[0] "cave": bands[0].enemies[0]: unknown enemy "ghost"`,
+				`stages[0] "cave": events[0]: ring needs a positive radius`,
+				`enemies[1] "imp": behavior shooter needs shooter parameters`,
+				`enemies[1] "imp": split: unknown enemy "golem"`,
+				`upgrades[0] "vigor": cost must be positive`,
+				`characters[0] "rogue": unknown weapon "dagger"`,
+				`characters[0] "rogue": passive: modifiers[0]: unknown stat "stealth"`,
+				`characters[0] "rogue": unlock: unknown kind "coins"`,
+				`characters: at least one character must have no unlock condition`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDefinitions([]byte(tt.json))
+			if err == nil {
+				t.Fatal("ParseDefinitions succeeded, want error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error does not contain %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSetDefinitionsAppliesOnRestart(t *testing.T) {
+	data := strings.Replace(string(defaultDefsJSON), `"hp": 100,`, `"hp": 250,`, 1)
+	defs, err := ParseDefinitions([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newTestGame()
+	hp := g.Player.MaxHP
+	g.SetDefinitions(defs)
+	if g.Definitions() == defs || g.Player.MaxHP != hp {
+		t.Fatal("SetDefinitions changed the current run")
+	}
+	if g.NextDefinitions() != defs {
+		t.Error("NextDefinitions() does not return the new definitions")
+	}
+
+	g.GameOver = true
+	g.Step(Input{Restart: true})
+	if g.Definitions() != defs {
+		t.Error("Definitions() did not switch on restart")
+	}
+	if want := hp + 150; g.Player.MaxHP != want {
+		t.Errorf("MaxHP = %d after restart, want %d", g.Player.MaxHP, want)
+	}
+}
+
+func TestDefinitionsNewEnemyKind(t *testing.T) {
+	// コードを変更せずに新しい敵を追加できる
+	data := strings.Replace(string(defaultDefsJSON),
+		`{"from": 0, "enemies": [{"id": "normal", "weight": 1}]}`,
+		`{"from": 0, "enemies": [{"id": "bat", "weight": 1}]}`, 1)
+	data = strings.Replace(data,
+		`"enemies": [`,
+		`"enemies": [{"id": "bat", "hp": 3, "speed": 5, "size": 12, "exp": 5, "score": 5, "color": "#404040"},`, 1)
+
+	defs, err := ParseDefinitions([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGameWithConfig(Config{Seed: 1, Defs: defs})
+	g.spawnEnemy()
+	if got := g.Enemies[0].Type; got != "bat" {
+		t.Errorf("spawned %q, want bat", got)
+	}
+}
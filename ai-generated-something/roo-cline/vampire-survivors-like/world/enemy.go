@@ -0,0 +1,222 @@
+package world
+
+import "math"
+
+// 敵の種類（定義ファイルの敵の id）
+type EnemyType string
+
+// 組み込みの敵
+const (
+	EnemyNormal EnemyType = "normal" // 通常の敵
+	EnemyFast   EnemyType = "fast"   // 速い敵
+	EnemyTank   EnemyType = "tank"   // 体力が多い敵
+	EnemyBoss   EnemyType = "boss"   // ボス敵
+)
+
+// 敵の基本パラメータ
+type EnemyParams struct {
+	Type  EnemyType `json:"id"`
+	HP    int       `json:"hp"`
+	Speed float64   `json:"speed"`
+	Size  float64   `json:"size"`
+	Exp   int       `json:"exp"`   // 倒した時に得られる経験値
+	Score int       `json:"score"` // 倒した時に得られるスコア
+	Color Color     `json:"color"` // 表示色
+
+	Damage          int     `json:"damage"`           // 触れたときにプレイヤーに与えるダメージ
+	KnockbackResist float64 `json:"knockback_resist"` // ノックバックを打ち消す割合（0〜1）
+	Boss            bool    `json:"boss"`             // 画面外でも消えず、位置を矢印で知らせる
+
+	Behavior EnemyBehaviorKind `json:"behavior"` // 動き方（省略時は chase）
+	Shooter  *ShooterParams    `json:"shooter"`  // behavior が shooter のとき必要
+	Charge   *ChargeParams     `json:"charge"`   // behavior が charger のとき必要
+	Split    *SplitParams      `json:"split"`    // 倒されたときに分裂する
+	Phases   []EnemyPhase      `json:"phases"`   // 残り HP で動き方を変える（ボス向け）
+}
+
+// Enemy は敵キャラクターを表す構造体です
+type Enemy struct {
+	X, Y     float64
+	Speed    float64
+	HP       int
+	MaxHP    int
+	Size     float64
+	Type     EnemyType
+	ExpValue int   // 倒した時に落とす宝石の経験値
+	Score    int   // 倒した時に得られるスコア
+	Color    Color // 表示色
+	Damage   int   // 触れたときにプレイヤーに与えるダメージ
+	Boss     bool  // ボスか
+
+	params     EnemyParams
+	phase      int         // 0 は最初の動き方、i は Phases[i-1]
+	charge     chargeState // 突進の状態
+	timer      int         // 弾や突進の残りティック数
+	dirX, dirY float64     // 突進の方向
+	kbX, kbY   float64     // ノックバックの速度（1ティックあたり）
+}
+
+// spawnEnemy は画面の外の辺に、時間経過に応じた種類の敵を1体出現させます
+func (g *Game) spawnEnemy() {
+	x, y := g.edgePosition(g.rng.Intn(4), g.rng.Float64())
+
+	// 時間経過で出現する敵の種類を変える
+	params := g.chooseEnemy(g.clock.Now())
+	g.Enemies = append(g.Enemies, newEnemy(params, x, y))
+}
+
+// edgePosition は画面の外の辺 side (0: 上, 1: 右, 2: 下, 3: 左) の
+// 割合 t (0〜1) の位置をワールド座標で返します
+func (g *Game) edgePosition(side int, t float64) (x, y float64) {
+	x, y = screenEdge(side, t)
+	return g.camera.X + x, g.camera.Y + y
+}
+
+// screenEdge は edgePosition の位置を画面の座標で返します
+func screenEdge(side int, t float64) (x, y float64) {
+	switch side {
+	case 0: // 上
+		return t * ScreenWidth, -30
+	case 1: // 右
+		return ScreenWidth + 30, t * ScreenHeight
+	case 2: // 下
+		return t * ScreenWidth, ScreenHeight + 30
+	default: // 左
+		return -30, t * ScreenHeight
+	}
+}
+
+func newEnemy(params EnemyParams, x, y float64) *Enemy {
+	return &Enemy{
+		X:        x,
+		Y:        y,
+		Speed:    params.Speed,
+		HP:       params.HP,
+		MaxHP:    params.HP,
+		Size:     params.Size,
+		Type:     params.Type,
+		ExpValue: params.Exp,
+		Score:    params.Score,
+		Color:    params.Color,
+		Damage:   params.Damage,
+		Boss:     params.Boss,
+		params:   params,
+	}
+}
+
+// chooseEnemy は gameTime 秒の時点で出現する敵を重みに従って選びます
+func (g *Game) chooseEnemy(gameTime float64) EnemyParams {
+	bands := g.wave.stage.Bands
+	band := bands[0]
+	for _, b := range bands[1:] {
+		if gameTime >= b.From {
+			band = b
+		}
+	}
+
+	choice := band.Enemies[0].Type
+	if len(band.Enemies) > 1 {
+		total := 0
+		for _, sw := range band.Enemies {
+			total += sw.Weight
+		}
+		n := g.rng.Intn(total)
+		for _, sw := range band.Enemies {
+			if n < sw.Weight {
+				choice = sw.Type
+				break
+			}
+			n -= sw.Weight
+		}
+	}
+	params, _ := g.defs.Enemy(choice)
+	return params
+}
+
+// damageEnemy は武器 weaponType による damage を敵に与えます
+// 倒した敵は取り除かれるまでの間もそれ以上ダメージを受けません
+func (g *Game) damageEnemy(enemy *Enemy, damage int, weaponType WeaponType) {
+	if enemy.HP <= 0 {
+		return
+	}
+	enemy.HP -= damage
+	g.stats.DamageDealt[weaponType] += damage
+	g.emit(Event{Kind: EventEnemyHit, X: enemy.X, Y: enemy.Y, Weapon: weaponType, Enemy: enemy.Type, Boss: enemy.Boss, Amount: damage})
+	if enemy.HP <= 0 {
+		g.stats.Kills[enemy.Type]++
+	}
+	g.checkEnemyDeath(enemy)
+}
+
+func (g *Game) checkEnemyDeath(enemy *Enemy) {
+	if enemy.HP <= 0 {
+		g.emit(Event{Kind: EventEnemyDeath, X: enemy.X, Y: enemy.Y, Enemy: enemy.Type, Boss: enemy.Boss})
+		g.Score += enemy.Score
+		g.dropPickups(enemy)
+		g.splitEnemy(enemy)
+	}
+}
+
+// splitEnemy は分裂する敵が倒れた位置に小さな敵を出現させます
+func (g *Game) splitEnemy(enemy *Enemy) {
+	split := enemy.params.Split
+	if split == nil {
+		return
+	}
+	params, _ := g.defs.Enemy(split.Into)
+	for i := 0; i < split.Count; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(split.Count)
+		x := enemy.X + math.Cos(angle)*enemy.Size/2
+		y := enemy.Y + math.Sin(angle)*enemy.Size/2
+		g.Enemies = append(g.Enemies, newEnemy(params, x, y))
+	}
+}
+
+// ノックバックの速度が1ティックごとに減る割合
+const knockbackDecay = 0.8
+
+// knockback は敵を (dirX, dirY) の方向に distance だけ押し戻します
+// 押し戻しは数ティックかけて減速しながら進み、その間は敵自身は動きません
+// 位置は updateEnemy で動かすので、武器の攻撃中に当たり判定のグリッドがずれることはありません
+func (e *Enemy) knockback(dirX, dirY, distance float64) {
+	v := distance * (1 - e.params.KnockbackResist) * (1 - knockbackDecay)
+	if v <= 0 {
+		return
+	}
+	e.kbX = dirX * v
+	e.kbY = dirY * v
+}
+
+// 重なった敵を押し離す強さ（重なりのうち1ティックで解消する割合）
+const separationStrength = 0.5
+
+// separateEnemies は重なった敵同士を押し離し、群れが1点に集まらないようにします
+// 呼び出す前にグリッドを作り直しておく必要があります
+func (g *Game) separateEnemies() {
+	for _, e := range g.Enemies {
+		if e.HP <= 0 {
+			continue
+		}
+		g.grid.query(e.X, e.Y, (e.Size+g.grid.maxSize)/2, func(other *Enemy) {
+			if other == e {
+				return
+			}
+			dx := e.X - other.X
+			dy := e.Y - other.Y
+			minDist := (e.Size + other.Size) / 2
+			distSq := dx*dx + dy*dy
+			if distSq >= minDist*minDist {
+				return
+			}
+			dist := math.Sqrt(distSq)
+			if dist == 0 {
+				// 完全に重なっているときは向きを決められないので横にずらす
+				dx, dy, dist = 1, 0, 1
+			}
+			// 大きい敵ほど押されにくい
+			push := (minDist - dist) * separationStrength * other.Size / (e.Size + other.Size)
+			e.X += dx / dist * push
+			e.Y += dy / dist * push
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package world
+
+// EventKind はゲーム中に起きた出来事の種類です
+type EventKind int
+
+const (
+	EventWeaponFire EventKind = iota // 武器が攻撃した（Weapon）
+	EventEnemyDeath                  // 敵を倒した（Enemy, X, Y, Boss）
+	EventLevelUp                     // プレイヤーのレベルが上がった
+	EventPlayerHit                   // プレイヤーがダメージを受けた（Amount, X, Y）
+	EventEnemyHit                    // 武器が敵に当たった（Weapon, Enemy, Amount, X, Y, Boss）
+)
+
+// Event はゲーム中に起きた出来事です。効果音や演出に使います
+// 種類によって使う項目が異なります
+type Event struct {
+	Kind   EventKind
+	X, Y   float64
+	Weapon WeaponType
+	Enemy  EnemyType
+	Boss   bool
+	Amount int
+}
+
+// emit は出来事を記録します
+func (g *Game) emit(e Event) {
+	g.events = append(g.events, e)
+}
+
+// Events は直前の Step で起きた出来事を返します
+// 返したスライスは次の Step で上書きされます
+func (g *Game) Events() []Event {
+	return g.events
+}
+
+// hurtPlayer はプレイヤーにダメージを与えます。無敵の間は何もしません
+func (g *Game) hurtPlayer(amount int) {
+	hp := g.Player.HP
+	if g.Player.damage(amount) {
+		g.emit(Event{Kind: EventPlayerHit, X: g.Player.X, Y: g.Player.Y, Amount: hp - g.Player.HP})
+	}
+}
@@ -0,0 +1,80 @@
+package world
+
+import "testing"
+
+// countEvents は直前の Step で起きた kind の出来事の数を返します
+func countEvents(g *Game, kind EventKind) int {
+	n := 0
+	for _, e := range g.Events() {
+		if e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEvents(t *testing.T) {
+	g := newTestGame()
+	enemy := placeEnemy(g, EnemyNormal, 10, 0)
+	enemy.HP = 1
+	enemy.Speed = 0
+	enemy.Damage = 0
+
+	// 最初の武器が攻撃するまで進めると、近くの敵を倒す
+	fires, hits, deaths := 0, 0, 0
+	for range TicksPerSecond * 2 {
+		g.Step(Input{})
+		fires += countEvents(g, EventWeaponFire)
+		hits += countEvents(g, EventEnemyHit)
+		deaths += countEvents(g, EventEnemyDeath)
+	}
+	if fires == 0 {
+		t.Error("no weapon fire events")
+	}
+	if hits != 1 {
+		t.Errorf("%d enemy hit events, want 1", hits)
+	}
+	if deaths != 1 {
+		t.Errorf("%d enemy death events, want 1", deaths)
+	}
+
+	g.Pickups = []*Pickup{{X: g.Player.X, Y: g.Player.Y, Kind: PickupExp, Value: g.Player.ExpToNextLevel}}
+	g.Step(Input{})
+	if n := countEvents(g, EventLevelUp); n != 1 {
+		t.Errorf("%d level up events, want 1", n)
+	}
+
+	// 出来事は次の Step で消える
+	g.Step(Input{})
+	if n := len(g.Events()); n != 0 {
+		t.Errorf("%d events carried over to the next step", n)
+	}
+}
+
+func TestPlayerHitEvent(t *testing.T) {
+	g := newTestGame()
+	g.Player.Weapons = nil
+	tank := placeEnemy(g, EnemyTank, 0, 0)
+	tank.Speed = 0
+	hp := g.Player.HP
+
+	g.Step(Input{})
+	var hit *Event
+	for _, e := range g.Events() {
+		if e.Kind == EventPlayerHit {
+			hit = &e
+		}
+	}
+	if hit == nil {
+		t.Fatal("no player hit event")
+	}
+	if hit.Amount != hp-g.Player.HP {
+		t.Errorf("Amount = %d, want %d", hit.Amount, hp-g.Player.HP)
+	}
+
+	// 無敵の間は出来事も起きない
+	g.Step(Input{})
+	if n := countEvents(g, EventPlayerHit); n != 0 {
+		t.Errorf("%d player hit events while invulnerable", n)
+	}
+}
@@ -0,0 +1,225 @@
+// Package world はヴァンパイアサバイバーズライクゲームのロジックです
+// 描画や入力デバイスには依存しないため、ウィンドウなしでも動かせます
+package world
+
+import (
+	"maps"
+	"math"
+	"math/rand"
+)
+
+const (
+	ScreenWidth  = 800
+	ScreenHeight = 600
+	playerSize   = 32 // プレイヤーの当たり判定の大きさ
+)
+
+// Game はゲームの状態を管理する構造体です
+type Game struct {
+	Player          *Player
+	Enemies         []*Enemy
+	Pickups         []*Pickup
+	EnemyBullets    []*EnemyBullet
+	GameOver        bool
+	Score           int
+	SkillOptions    []SkillOption
+	SkillActions    SkillActions // このプレイで残っている引き直し・飛ばし・除外の回数
+	ChoosingSkill   bool
+	PendingLevelUps int // スキルをまだ選んでいないレベルアップの数（選択中のものを含む）
+	clock           Clock
+	rng             *rand.Rand
+	stats           Stats
+	defs            *Definitions
+	grid            enemyGrid // 当たり判定用の空間分割
+	wave            director  // 敵の出現を管理する
+	camera          Camera
+	events          []Event             // 直前の Step で起きた出来事
+	banished        map[skillKey]bool   // このプレイで二度と出さない選択肢
+	nextDefs        *Definitions        // 次のリスタートで使う定義
+	upgrades        map[UpgradeType]int // 次のリスタートで使う永続的な強化
+	nextCharacter   CharacterType       // 次のリスタートで使うキャラクター
+}
+
+// Config はゲームを作成するときの設定です
+type Config struct {
+	Seed  int64
+	Clock Clock        // nil なら固定ティックのクロック
+	Defs  *Definitions // nil なら組み込みの定義
+	Stage string       // 空なら定義の最初のステージ
+
+	Upgrades  map[UpgradeType]int // 永続的な強化のレベル
+	Character CharacterType       // 空なら定義の最初のキャラクター
+}
+
+// NewGame は固定ティックのクロックと組み込みの定義で新しいゲームを作成します
+// 同じ seed と同じ入力からは必ず同じ展開になります
+func NewGame(seed int64) *Game {
+	return NewGameWithConfig(Config{Seed: seed})
+}
+
+// NewGameWithConfig は cfg に従って新しいゲームを作成します
+func NewGameWithConfig(cfg Config) *Game {
+	if cfg.Clock == nil {
+		cfg.Clock = NewFixedClock(1.0 / TicksPerSecond)
+	}
+	if cfg.Defs == nil {
+		cfg.Defs = DefaultDefinitions()
+	}
+	stage, ok := cfg.Defs.Stage(cfg.Stage)
+	if !ok {
+		stage = cfg.Defs.Stages[0]
+	}
+	player := newPlayer(cfg.Defs, cfg.Defs.character(cfg.Character))
+	player.permanent = upgradeModifiers(cfg.Defs, cfg.Upgrades)
+	player.recalcStats()
+	return &Game{
+		Player:       player,
+		Enemies:      make([]*Enemy, 0),
+		Score:        0,
+		SkillActions: cfg.Defs.LevelUp.actions(),
+		clock:        cfg.Clock,
+		rng:          rand.New(rand.NewSource(cfg.Seed)),
+		stats:        newStats(),
+		defs:         cfg.Defs,
+		wave:         newDirector(stage),
+		camera:       cameraAt(player.X, player.Y),
+		banished:     make(map[skillKey]bool),
+
+		nextDefs:      cfg.Defs,
+		upgrades:      maps.Clone(cfg.Upgrades),
+		nextCharacter: cfg.Character,
+	}
+}
+
+// Time はゲーム開始からの経過時間（秒）を返します
+func (g *Game) Time() float64 {
+	return g.clock.Now()
+}
+
+// Definitions はこのゲームで使っている定義を返します
+func (g *Game) Definitions() *Definitions {
+	return g.defs
+}
+
+// SetDefinitions は使う定義を切り替えます。次のリスタートから反映されます
+// 遊んでいるゲームの途中では変えないので、リプレイでも同じ展開になります
+func (g *Game) SetDefinitions(defs *Definitions) {
+	g.nextDefs = defs
+}
+
+// NextDefinitions は次のリスタートで使う定義を返します
+func (g *Game) NextDefinitions() *Definitions {
+	return g.nextDefs
+}
+
+// SetUpgrades は永続的な強化のレベルを設定します。次のリスタートから反映されます
+func (g *Game) SetUpgrades(levels map[UpgradeType]int) {
+	g.upgrades = maps.Clone(levels)
+}
+
+// Upgrades は次のリスタートで使う永続的な強化のレベルを返します
+func (g *Game) Upgrades() map[UpgradeType]int {
+	return maps.Clone(g.upgrades)
+}
+
+// restart は新しいゲームを開始します
+// 次のゲームのシードも乱数から決めるため、リプレイでも同じ展開になります
+func (g *Game) restart() {
+	*g = *NewGameWithConfig(Config{Seed: g.rng.Int63(), Defs: g.nextDefs, Stage: g.wave.stage.ID, Upgrades: g.upgrades, Character: g.nextCharacter})
+}
+
+// Step は入力 in で1ティック分ゲームを進めます
+func (g *Game) Step(in Input) {
+	clear(g.events)
+	g.events = g.events[:0]
+
+	if g.GameOver {
+		if in.Restart {
+			g.restart()
+		}
+		return
+	}
+
+	if g.ChoosingSkill {
+		g.chooseSkill(in)
+		return
+	}
+
+	// スキル選択中やゲームオーバー中は時間を止めるため、ここで進める
+	g.clock.Tick()
+	now := g.clock.Now()
+
+	// プレイヤーの移動処理（斜めでも速さは同じ）
+	moveX, moveY := in.Move()
+	g.Player.X += moveX * g.Player.Speed
+	g.Player.Y += moveY * g.Player.Speed
+	g.camera = cameraAt(g.Player.X, g.Player.Y)
+
+	// 敵の生成
+	g.updateWaves(now)
+
+	// 武器の攻撃処理
+	// グリッドは敵の位置が変わる処理（出現・移動・押し離し）のあとに1回ずつ作り直す
+	// ノックバックは速度として updateEnemy で反映するので、武器の攻撃中は敵の位置が変わらず、
+	// 後の武器や弾も同じグリッドで当たり判定ができる（分裂して出た敵は次のティックから当たる）
+	g.grid.rebuild(g.Enemies)
+	for _, weapon := range g.Player.Weapons {
+		interval := weapon.Params.AttackInterval * g.Player.Stat(StatCooldown)
+		if now-weapon.lastAttackTime >= interval {
+			if g.attack(weapon) {
+				g.emit(Event{Kind: EventWeaponFire, X: g.Player.X, Y: g.Player.Y, Weapon: weapon.Params.WeaponType})
+			}
+			weapon.lastAttackTime = now
+		}
+		g.updateProjectiles(weapon)
+	}
+
+	// アイテムの回収
+	g.updatePickups()
+	g.Player.regenerate()
+
+	// 敵の更新
+	for _, enemy := range g.Enemies {
+		g.updateEnemy(enemy)
+	}
+	// 移動とノックバックで位置が変わったので、押し離しの前に作り直す
+	g.grid.rebuild(g.Enemies)
+	g.separateEnemies()
+	// 押し離しで位置が変わったので、プレイヤーとの衝突判定の前に作り直す
+	g.grid.rebuild(g.Enemies)
+	// 無敵の時間は敵の弾と敵との当たり判定のあとで減らす
+	// ダメージを受けたティックは減らさないので、無敵は設定どおりのティック数だけ続く
+	wasInvulnerable := g.Player.Invulnerable()
+	g.updateEnemyBullets()
+
+	// プレイヤーとの衝突判定
+	// 同時に触れている敵のうち、最も大きいダメージを1回だけ受ける
+	contact := 0
+	g.grid.query(g.Player.X, g.Player.Y, g.grid.maxSize/2+playerSize/2, func(enemy *Enemy) {
+		dx := g.Player.X - enemy.X
+		dy := g.Player.Y - enemy.Y
+		dist := math.Sqrt(dx*dx + dy*dy)
+		if dist < enemy.Size/2+playerSize/2 {
+			contact = max(contact, enemy.Damage)
+		}
+	})
+	if contact > 0 {
+		g.hurtPlayer(contact)
+	}
+	if wasInvulnerable {
+		g.Player.invulnerable--
+	}
+	if g.Player.HP <= 0 {
+		g.GameOver = true
+		return
+	}
+
+	// 倒した敵と、画面から遠く離れた敵を取り除く（ボスは倒されるまで残る）
+	newEnemies := make([]*Enemy, 0, len(g.Enemies))
+	for _, enemy := range g.Enemies {
+		if enemy.HP > 0 && (enemy.Boss || g.camera.Contains(enemy.X, enemy.Y, despawnMargin)) {
+			newEnemies = append(newEnemies, enemy)
+		}
+	}
+	g.Enemies = newEnemies
+}
@@ -0,0 +1,344 @@
+package world
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// newTestGame は敵のいない状態のゲームを作成します
+func newTestGame() *Game {
+	return NewGame(1)
+}
+
+// placeEnemy はプレイヤーから (dx, dy) の位置に敵を置きます
+func placeEnemy(g *Game, enemyType EnemyType, dx, dy float64) *Enemy {
+	params, _ := g.defs.Enemy(enemyType)
+	enemy := newEnemy(params, g.Player.X+dx, g.Player.Y+dy)
+	g.Enemies = append(g.Enemies, enemy)
+	g.grid.rebuild(g.Enemies)
+	return enemy
+}
+
+func TestGainExp(t *testing.T) {
+	curve := ExpCurve{Base: 100, Growth: 100}
+	p := newPlayer(DefaultDefinitions(), CharacterParams{})
+
+	if n := p.gainExp(p.ExpToNextLevel-1, curve); n != 0 {
+		t.Fatalf("leveled up %d times before reaching ExpToNextLevel", n)
+	}
+	if n := p.gainExp(1, curve); n != 1 {
+		t.Fatalf("leveled up %d times at ExpToNextLevel, want 1", n)
+	}
+	if p.Level != 2 {
+		t.Errorf("Level = %d, want 2", p.Level)
+	}
+	if p.ExpToNextLevel != 200 {
+		t.Errorf("ExpToNextLevel = %d, want 200", p.ExpToNextLevel)
+	}
+}
+
+func TestGainExpMultipleLevels(t *testing.T) {
+	curve := ExpCurve{Base: 100, Growth: 100, Table: []int{50}}
+	p := newPlayer(DefaultDefinitions(), CharacterParams{})
+	p.ExpToNextLevel = curve.expToNextLevel(1)
+
+	// 50 + 200 + 300 = 550 でレベル4になり、残りの 30 は持ち越す
+	if n := p.gainExp(580, curve); n != 3 {
+		t.Fatalf("leveled up %d times, want 3", n)
+	}
+	if p.Level != 4 || p.Exp != 30 || p.ExpToNextLevel != 400 {
+		t.Errorf("Level, Exp, ExpToNextLevel = %d, %d, %d, want 4, 30, 400", p.Level, p.Exp, p.ExpToNextLevel)
+	}
+}
+
+func TestLevelUpsAreChosenInSequence(t *testing.T) {
+	g := newTestGame()
+	boss := placeEnemy(g, EnemyBoss, 10, 0)
+	normal := placeEnemy(g, EnemyNormal, -10, 0)
+	boss.ExpValue = 100 + 200 + 300
+
+	g.damageEnemy(boss, boss.HP, WeaponMelee)
+	g.damageEnemy(normal, normal.HP, WeaponMelee)
+	g.updatePickups()
+
+	if !g.ChoosingSkill || g.PendingLevelUps != 3 {
+		t.Fatalf("ChoosingSkill, PendingLevelUps = %v, %d, want true, 3", g.ChoosingSkill, g.PendingLevelUps)
+	}
+	if g.Player.Exp != normal.ExpValue {
+		t.Errorf("Exp = %d, want %d", g.Player.Exp, normal.ExpValue)
+	}
+
+	for i := 3; i > 0; i-- {
+		if !g.ChoosingSkill {
+			t.Fatalf("skill choice ended with %d level ups left", i)
+		}
+		if len(g.SkillOptions) != skillChoices {
+			t.Fatalf("len(SkillOptions) = %d, want %d", len(g.SkillOptions), skillChoices)
+		}
+		g.SkillOptions[0] = SkillOption{Type: SkillPassive, Passive: "wings"}
+		g.Step(Input{Choice: 1})
+	}
+	if g.ChoosingSkill || g.PendingLevelUps != 0 {
+		t.Errorf("ChoosingSkill, PendingLevelUps = %v, %d, want false, 0", g.ChoosingSkill, g.PendingLevelUps)
+	}
+	if wings := g.Player.passive("wings"); wings == nil || wings.Level != 3 {
+		t.Errorf("wings = %+v, want level 3; each level up should apply one skill", wings)
+	}
+}
+
+func TestMeleeDamagesEnemiesInRange(t *testing.T) {
+	g := newTestGame()
+	weapon := g.Player.Weapons[0]
+	near := placeEnemy(g, EnemyTank, weapon.Params.AttackRange-1, 0)
+	far := placeEnemy(g, EnemyTank, weapon.Params.AttackRange+1, 0)
+
+	g.attack(weapon)
+
+	if want := near.MaxHP - weapon.Params.AttackDamage; near.HP != want {
+		t.Errorf("enemy in range: HP = %d, want %d", near.HP, want)
+	}
+	if far.HP != far.MaxHP {
+		t.Errorf("enemy out of range: HP = %d, want %d", far.HP, far.MaxHP)
+	}
+	if got := g.Stats().DamageDealt[WeaponMelee]; got != weapon.Params.AttackDamage {
+		t.Errorf("DamageDealt[melee] = %d, want %d", got, weapon.Params.AttackDamage)
+	}
+}
+
+func TestEnemyDeath(t *testing.T) {
+	g := newTestGame()
+	enemy := placeEnemy(g, EnemyNormal, 10, 0)
+
+	g.damageEnemy(enemy, enemy.HP, WeaponMelee)
+
+	if g.Score != enemy.Score {
+		t.Errorf("Score = %d, want %d", g.Score, enemy.Score)
+	}
+
+	// 経験値は落とした宝石を拾ったときに入る
+	if g.Player.Exp != 0 {
+		t.Errorf("Exp = %d before picking up the gem, want 0", g.Player.Exp)
+	}
+	g.updatePickups()
+	if g.Player.Exp != enemy.ExpValue {
+		t.Errorf("Exp = %d, want %d", g.Player.Exp, enemy.ExpValue)
+	}
+	if got := g.Stats().Kills[EnemyNormal]; got != 1 {
+		t.Errorf("Kills[normal] = %d, want 1", got)
+	}
+
+	// 倒した敵は次のティックで取り除かれる
+	g.Step(Input{})
+	for _, e := range g.Enemies {
+		if e == enemy {
+			t.Error("dead enemy was not removed")
+		}
+	}
+}
+
+func TestEnemyDeathStartsSkillChoice(t *testing.T) {
+	g := newTestGame()
+	g.Player.Exp = g.Player.ExpToNextLevel - 1
+	enemy := placeEnemy(g, EnemyNormal, 10, 0)
+
+	g.damageEnemy(enemy, enemy.HP, WeaponMelee)
+	g.updatePickups()
+
+	if !g.ChoosingSkill {
+		t.Fatal("ChoosingSkill = false after level up")
+	}
+	if len(g.SkillOptions) != 3 {
+		t.Fatalf("len(SkillOptions) = %d, want 3", len(g.SkillOptions))
+	}
+
+	// スキル選択中は時間が止まる
+	now := g.Time()
+	g.Step(Input{})
+	if g.Time() != now {
+		t.Errorf("time advanced while choosing skill: %v -> %v", now, g.Time())
+	}
+
+	g.Step(Input{Choice: 1})
+	if g.ChoosingSkill {
+		t.Error("ChoosingSkill = true after choosing a skill")
+	}
+}
+
+func TestPlayerDeath(t *testing.T) {
+	g := newTestGame()
+	g.Player.HP = 1
+	placeEnemy(g, EnemyBoss, 0, 0)
+
+	g.Step(Input{})
+
+	if !g.GameOver {
+		t.Fatal("GameOver = false after HP reached 0")
+	}
+
+	// ゲームオーバー中は R でリスタートするまで何も起きない
+	now := g.Time()
+	g.Step(Input{MoveY: -1})
+	if !g.GameOver || g.Time() != now {
+		t.Error("game advanced after game over")
+	}
+	g.Step(Input{Restart: true})
+	if g.GameOver || g.Player.HP != g.Player.MaxHP {
+		t.Error("game did not restart")
+	}
+}
+
+func TestContactDamageAndInvulnerability(t *testing.T) {
+	g := newTestGame()
+	// 武器のノックバックで敵が離れないよう、武器を持たせない
+	g.Player.Weapons = nil
+	hp := g.Player.HP
+	placeEnemy(g, EnemyNormal, 0, 0)
+	tank := placeEnemy(g, EnemyTank, 0, 0)
+	tank.Speed = 0
+
+	// 同時に触れている敵のうち最も大きいダメージだけを受ける
+	g.Step(Input{})
+	if want := hp - tank.Damage; g.Player.HP != want {
+		t.Fatalf("HP = %d, want %d", g.Player.HP, want)
+	}
+	if !g.Player.Invulnerable() {
+		t.Fatal("Invulnerable() = false after being hit")
+	}
+
+	// 無敵の間は触れていてもダメージを受けない。無敵は設定した秒数のティック数だけ続く
+	for range ticks(g.defs.Player.Invulnerability) {
+		g.Step(Input{})
+	}
+	if want := hp - tank.Damage; g.Player.HP != want {
+		t.Fatalf("HP = %d while invulnerable, want %d", g.Player.HP, want)
+	}
+	g.Step(Input{})
+	if want := hp - 2*tank.Damage; g.Player.HP != want {
+		t.Errorf("HP = %d after invulnerability ended, want %d", g.Player.HP, want)
+	}
+}
+
+func TestReplayReproducesRun(t *testing.T) {
+	const ticks = TicksPerSecond * 120
+
+	// 強化した状態で入力を記録しながら遊ぶ
+	cfg := Config{Seed: 42, Upgrades: map[UpgradeType]int{"might": 2, "max_hp": 1}, Character: "mage"}
+	g := NewGameWithConfig(cfg)
+	rec := NewReplayRecorder(cfg)
+	for i := 0; i < ticks; i++ {
+		in := Input{MoveX: float64(i/90%2*2 - 1), MoveY: -float64(i / 150 % 2), Choice: i%3 + 1}
+		rec.Record(in)
+		g.Step(in)
+	}
+
+	var buf bytes.Buffer
+	if _, err := rec.Replay().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Ticks() != ticks {
+		t.Fatalf("Ticks() = %d, want %d", replay.Ticks(), ticks)
+	}
+
+	if !reflect.DeepEqual(replay.Upgrades, cfg.Upgrades) || replay.Character != cfg.Character {
+		t.Fatalf("Upgrades, Character = %v, %q, want %v, %q", replay.Upgrades, replay.Character, cfg.Upgrades, cfg.Character)
+	}
+	// ステージを省略して記録しても、遊んだステージの id が残る
+	if want := g.Stage().ID; replay.Stage != want {
+		t.Fatalf("Stage = %q, want %q", replay.Stage, want)
+	}
+
+	// 同じシード・定義・ステージ・強化・キャラクター・入力で再生すると同じ結果になる
+	replayCfg, err := replay.Config(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewGameWithConfig(replayCfg)
+	input := NewReplayInput(replay)
+	for {
+		in, ok := input.Next()
+		if !ok {
+			break
+		}
+		r.Step(in)
+	}
+
+	if !reflect.DeepEqual(g.Stats(), r.Stats()) {
+		t.Errorf("replayed stats differ:\n got %+v\nwant %+v", r.Stats(), g.Stats())
+	}
+}
+
+func TestReplayConfigChecksDefinitions(t *testing.T) {
+	replay := NewReplayRecorder(Config{Seed: 1}).Replay()
+
+	// 内容が同じなら、読み込み直した定義でも再生できる
+	same, err := ParseDefinitions(defaultDefsJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replay.Config(same); err != nil {
+		t.Errorf("Config(same definitions) = %v", err)
+	}
+
+	other := *DefaultDefinitions()
+	other.LevelUp.Rerolls++
+	if _, err := replay.Config(&other); !errors.Is(err, ErrDefinitionsMismatch) {
+		t.Errorf("Config(other definitions) = %v, want ErrDefinitionsMismatch", err)
+	}
+}
+
+func TestReadReplayOldVersion(t *testing.T) {
+	// ステージと定義を記録していなかった頃のリプレイは同じ展開にならないので読み込まない
+	data := []byte{'V', 'S', 'R', 'P', 5, 84, 0, 0, 0, 0, 0, 5}
+	if _, err := ReadReplay(bytes.NewReader(data)); !errors.Is(err, ErrInvalidReplay) {
+		t.Errorf("ReadReplay(version 5) = %v, want ErrInvalidReplay", err)
+	}
+}
+
+func TestInputEncoding(t *testing.T) {
+	for _, in := range []Input{
+		{},
+		{MoveX: -1, MoveY: -1},
+		{MoveX: 1, MoveY: 0.25, Restart: true},
+		{MoveX: 0.3, MoveY: -0.8},
+		{Choice: 3},
+		{Choice: 2, SkillAction: SkillActionBanish},
+		{SkillAction: SkillActionSkip, Restart: true},
+	} {
+		got := decodeInput(in.encode())
+		gx, gy := got.Move()
+		wx, wy := in.Move()
+		if gx != wx || gy != wy || got.Choice != in.Choice || got.SkillAction != in.SkillAction || got.Restart != in.Restart {
+			t.Errorf("decodeInput(%+v.encode()) = %+v", in, got)
+		}
+	}
+}
+
+func TestDiagonalMovementIsNormalized(t *testing.T) {
+	for _, in := range []Input{{MoveX: 1}, {MoveX: 1, MoveY: 1}, {MoveX: -3, MoveY: 4}} {
+		x, y := in.Move()
+		if l := math.Hypot(x, y); math.Abs(l-1) > 0.01 {
+			t.Errorf("%+v.Move() has length %v, want 1", in, l)
+		}
+	}
+
+	g := newTestGame()
+	x, y := g.Player.X, g.Player.Y
+	g.Step(Input{MoveX: 1, MoveY: 1})
+	if d := math.Hypot(g.Player.X-x, g.Player.Y-y); math.Abs(d-g.Player.Speed) > 0.05 {
+		t.Errorf("moved %v diagonally in a tick, want speed %v", d, g.Player.Speed)
+	}
+
+	// アナログの入力は傾けた分だけ動く
+	x = g.Player.X
+	g.Step(Input{MoveX: 0.5})
+	if d := g.Player.X - x; math.Abs(d-g.Player.Speed/2) > 0.05 {
+		t.Errorf("moved %v with half tilt, want %v", d, g.Player.Speed/2)
+	}
+}
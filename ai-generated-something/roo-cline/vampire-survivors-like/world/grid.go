@@ -0,0 +1,128 @@
+package world
+
+import "math"
+
+// 空間分割グリッドのセルの大きさ
+const gridCellSize = 64.0
+
+type gridCell struct {
+	x, y int
+}
+
+func cellOf(x, y float64) gridCell {
+	return gridCell{int(math.Floor(x / gridCellSize)), int(math.Floor(y / gridCellSize))}
+}
+
+// enemyGrid は敵を一定サイズのセルに分けて管理する一様グリッドです
+// 当たり判定の候補を近くのセルにいる敵だけに絞り込むために使います
+type enemyGrid struct {
+	cells   map[gridCell][]*Enemy
+	maxSize float64  // 登録されている敵の最大サイズ
+	min     gridCell // 敵がいるセルの範囲
+	max     gridCell
+	empty   bool
+}
+
+// rebuild は enemies の現在位置でグリッドを作り直します
+func (g *enemyGrid) rebuild(enemies []*Enemy) {
+	if g.cells == nil {
+		g.cells = make(map[gridCell][]*Enemy)
+	}
+	// セルのスライスは使い回すが、空のセルが増えすぎたら捨てる
+	if len(g.cells) > 4*len(enemies)+64 {
+		clear(g.cells)
+	}
+	for k, v := range g.cells {
+		g.cells[k] = v[:0]
+	}
+
+	g.maxSize = 0
+	g.empty = len(enemies) == 0
+	for i, e := range enemies {
+		c := cellOf(e.X, e.Y)
+		g.cells[c] = append(g.cells[c], e)
+		g.maxSize = math.Max(g.maxSize, e.Size)
+		if i == 0 {
+			g.min, g.max = c, c
+			continue
+		}
+		g.min.x, g.min.y = min(g.min.x, c.x), min(g.min.y, c.y)
+		g.max.x, g.max.y = max(g.max.x, c.x), max(g.max.y, c.y)
+	}
+}
+
+// query は (x, y) から距離 r 以内にいる可能性のある生きている敵について fn を呼びます
+// 候補を絞り込むだけなので、正確な距離の判定は呼び出し側で行います
+func (g *enemyGrid) query(x, y, r float64, fn func(*Enemy)) {
+	if g.empty {
+		return
+	}
+	lo := cellOf(x-r, y-r)
+	hi := cellOf(x+r, y+r)
+	for cy := max(lo.y, g.min.y); cy <= min(hi.y, g.max.y); cy++ {
+		for cx := max(lo.x, g.min.x); cx <= min(hi.x, g.max.x); cx++ {
+			for _, e := range g.cells[gridCell{cx, cy}] {
+				if e.HP > 0 {
+					fn(e)
+				}
+			}
+		}
+	}
+}
+
+// nearest は (x, y) に最も近い生きている敵を返します。敵がいなければ nil を返します
+func (g *enemyGrid) nearest(x, y float64) *Enemy {
+	if g.empty {
+		return nil
+	}
+
+	var best *Enemy
+	bestDist := math.MaxFloat64
+	visit := func(c gridCell) {
+		for _, e := range g.cells[c] {
+			if e.HP <= 0 {
+				continue
+			}
+			dx := e.X - x
+			dy := e.Y - y
+			if dist := math.Sqrt(dx*dx + dy*dy); dist < bestDist {
+				bestDist = dist
+				best = e
+			}
+		}
+	}
+
+	// 中心のセルから外側に向かってリング状に探す
+	c := cellOf(x, y)
+	maxRing := max(c.x-g.min.x, g.max.x-c.x, c.y-g.min.y, g.max.y-c.y)
+	for ring := 0; ring <= maxRing; ring++ {
+		// ring 番目のリングにいる敵は少なくとも (ring-1) セル分離れている
+		if best != nil && bestDist <= float64(ring-1)*gridCellSize {
+			break
+		}
+		if ring == 0 {
+			visit(c)
+			continue
+		}
+		for dx := -ring; dx <= ring; dx++ {
+			visit(gridCell{c.x + dx, c.y - ring})
+			visit(gridCell{c.x + dx, c.y + ring})
+		}
+		for dy := -ring + 1; dy <= ring-1; dy++ {
+			visit(gridCell{c.x - ring, c.y + dy})
+			visit(gridCell{c.x + ring, c.y + dy})
+		}
+	}
+	return best
+}
+
+// enemiesInRange は (x, y) から距離 r 以内にいる敵について fn を呼びます
+func (g *Game) enemiesInRange(x, y, r float64, fn func(*Enemy)) {
+	g.grid.query(x, y, r, func(e *Enemy) {
+		dx := e.X - x
+		dy := e.Y - y
+		if math.Sqrt(dx*dx+dy*dy) <= r {
+			fn(e)
+		}
+	})
+}
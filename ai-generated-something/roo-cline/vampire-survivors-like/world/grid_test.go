@@ -0,0 +1,121 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// randomEnemies は (0, 0) を中心とした一辺 spread の正方形に n 体の敵を置きます
+func randomEnemies(rng *rand.Rand, n int, spread float64) []*Enemy {
+	enemies := make([]*Enemy, n)
+	for i := range enemies {
+		enemies[i] = &Enemy{
+			X:     (rng.Float64() - 0.5) * spread,
+			Y:     (rng.Float64() - 0.5) * spread,
+			Size:  float64(16 + rng.Intn(40)),
+			HP:    math.MaxInt32,
+			MaxHP: math.MaxInt32,
+			Speed: 1,
+			Type:  EnemyNormal,
+		}
+	}
+	return enemies
+}
+
+func TestEnemyGridQuery(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	enemies := randomEnemies(rng, 500, 2000)
+	var grid enemyGrid
+	grid.rebuild(enemies)
+
+	for i := 0; i < 100; i++ {
+		x := (rng.Float64() - 0.5) * 2400
+		y := (rng.Float64() - 0.5) * 2400
+		r := rng.Float64() * 300
+
+		found := make(map[*Enemy]bool)
+		grid.query(x, y, r, func(e *Enemy) { found[e] = true })
+		for _, e := range enemies {
+			if math.Hypot(e.X-x, e.Y-y) <= r && !found[e] {
+				t.Fatalf("query(%v, %v, %v) missed enemy at (%v, %v)", x, y, r, e.X, e.Y)
+			}
+		}
+	}
+}
+
+func TestEnemyGridNearest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	enemies := randomEnemies(rng, 500, 2000)
+	var grid enemyGrid
+	grid.rebuild(enemies)
+
+	for i := 0; i < 100; i++ {
+		x := (rng.Float64() - 0.5) * 4000
+		y := (rng.Float64() - 0.5) * 4000
+
+		want := math.MaxFloat64
+		for _, e := range enemies {
+			want = math.Min(want, math.Hypot(e.X-x, e.Y-y))
+		}
+		got := grid.nearest(x, y)
+		if d := math.Hypot(got.X-x, got.Y-y); d != want {
+			t.Fatalf("nearest(%v, %v) is %v away, want %v", x, y, d, want)
+		}
+	}
+
+	grid.rebuild(nil)
+	if e := grid.nearest(0, 0); e != nil {
+		t.Errorf("nearest with no enemies = %+v, want nil", e)
+	}
+}
+
+// countIn は placed のうち enemies に残っている敵の数を返します
+func countIn(enemies, placed []*Enemy) int {
+	alive := make(map[*Enemy]bool, len(enemies))
+	for _, e := range enemies {
+		alive[e] = true
+	}
+	n := 0
+	for _, e := range placed {
+		if alive[e] {
+			n++
+		}
+	}
+	return n
+}
+
+// BenchmarkStep はすべての武器を持った状態で敵の数を増やしたときの1ティックの処理時間を測ります
+func BenchmarkStep(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000, 10000} {
+		b.Run(fmt.Sprintf("enemies=%d", n), func(b *testing.B) {
+			g := NewGame(1)
+			for _, id := range []WeaponType{WeaponRanged, WeaponAura, WeaponSpiral} {
+				params, _ := g.defs.Weapon(id)
+				g.Player.Weapons = append(g.Player.Weapons, newWeapon(params))
+			}
+			g.Player.HP = math.MaxInt32
+			g.Enemies = randomEnemies(rand.New(rand.NewSource(1)), n, 4000)
+			for _, e := range g.Enemies {
+				e.X += g.Player.X
+				e.Y += g.Player.Y
+				// 画面から離れた敵は取り除かれるので、取り除かれないボスとして置く
+				e.Boss = true
+			}
+			placed := slices.Clone(g.Enemies)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				g.Step(Input{})
+			}
+			b.StopTimer()
+
+			// 途中で敵が減っていたら、測っているのは n 体のときの処理時間ではない
+			if left := countIn(g.Enemies, placed); left != n {
+				b.Fatalf("%d of %d enemies are left after %d ticks", left, n, b.N)
+			}
+		})
+	}
+}
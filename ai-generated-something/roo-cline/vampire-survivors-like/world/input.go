@@ -0,0 +1,84 @@
+package world
+
+import "math"
+
+// Input は1ティック分のプレイヤー入力です
+type Input struct {
+	MoveX, MoveY float64 // 移動の方向と強さ（-1〜1）。長さが1を超えると1に縮める
+	Choice       int     // スキル選択（0: なし, 1-3: 選択肢の番号）
+	SkillAction  SkillAction
+	Restart      bool
+}
+
+// SkillAction はスキル選択中に、選択肢を選ぶ代わりに行う操作です
+// 1回のプレイで使える回数は定義の level_up で決まります
+type SkillAction int
+
+const (
+	SkillActionNone   SkillAction = iota
+	SkillActionReroll             // 選択肢を引き直す
+	SkillActionSkip               // スキルを選ばずにレベルアップを終える
+	SkillActionBanish             // Choice 番目の選択肢をこのプレイでは二度と出さず、選択肢を引き直す
+)
+
+// 移動の入力の精度。リプレイには -moveSteps〜moveSteps の整数で保存します
+const moveSteps = 127
+
+// Move は移動の方向を返します
+// 斜めでも速くならないよう長さを1以下にし、リプレイと同じ精度に丸めます
+func (in Input) Move() (x, y float64) {
+	x, y = in.MoveX, in.MoveY
+	// 丸めた値をもう一度丸めても変わらないよう、丸めの誤差の分だけ余裕を持たせる
+	if l := math.Hypot(x, y); l > 1+1.0/moveSteps {
+		x /= l
+		y /= l
+	}
+	return quantizeMove(x), quantizeMove(y)
+}
+
+func quantizeMove(v float64) float64 {
+	return math.Round(max(-1, min(1, v))*moveSteps) / moveSteps
+}
+
+// 入力をリプレイ用のバイト列に詰めたもの
+//
+//	フラグ(1バイト) | 横の移動(int8) | 縦の移動(int8)
+type encodedInput [3]byte
+
+// フラグのビット
+const (
+	inputRestart     byte = 1 << iota
+	inputChoiceShift      = 1 // 1-4ビット目に選択肢の番号を入れる
+	inputActionShift      = 5 // 5-6ビット目にスキル選択の操作を入れる
+)
+
+// encode は入力をリプレイ用のバイト列に変換します
+func (in Input) encode() encodedInput {
+	var b encodedInput
+	if in.Restart {
+		b[0] |= inputRestart
+	}
+	b[0] |= byte(in.Choice&0xf) << inputChoiceShift
+	b[0] |= byte(in.SkillAction&0x3) << inputActionShift
+	x, y := in.Move()
+	b[1] = byte(int8(math.Round(x * moveSteps)))
+	b[2] = byte(int8(math.Round(y * moveSteps)))
+	return b
+}
+
+// decodeInput は encode で変換したバイト列から入力を復元します
+func decodeInput(b encodedInput) Input {
+	return Input{
+		MoveX:       float64(int8(b[1])) / moveSteps,
+		MoveY:       float64(int8(b[2])) / moveSteps,
+		Restart:     b[0]&inputRestart != 0,
+		Choice:      int(b[0]>>inputChoiceShift) & 0xf,
+		SkillAction: SkillAction(b[0]>>inputActionShift) & 0x3,
+	}
+}
+
+// InputSource は毎ティックの入力を供給します
+type InputSource interface {
+	// Next は次のティックの入力を返します。入力が尽きた場合は false を返します
+	Next() (Input, bool)
+}
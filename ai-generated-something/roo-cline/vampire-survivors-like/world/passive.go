@@ -0,0 +1,57 @@
+package world
+
+// パッシブアイテムの種類（定義ファイルのパッシブアイテムの id）
+type PassiveType string
+
+// パッシブアイテムのパラメータ
+type PassiveParams struct {
+	PassiveType PassiveType `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Modifiers   []Modifier  `json:"modifiers"` // 1レベルあたりのステータスの補正
+	MaxLevel    int         `json:"max_level"`
+}
+
+// PassiveItem はプレイヤーが持っているパッシブアイテムです
+type PassiveItem struct {
+	Params PassiveParams
+	Level  int
+}
+
+// passive は持っているパッシブアイテムを返します。持っていなければ nil を返します
+func (p *Player) passive(id PassiveType) *PassiveItem {
+	for _, item := range p.Passives {
+		if item.Params.PassiveType == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// Evolution は最大レベルの武器とパッシブアイテムが揃ったときの武器の進化です
+type Evolution struct {
+	Weapon  WeaponType  `json:"weapon"`
+	Passive PassiveType `json:"passive"`
+	Into    WeaponType  `json:"into"`
+}
+
+// evolveWeapons は条件を満たした武器を進化させます
+// 進化しても攻撃の間隔・向き・飛んでいる弾は引き継ぎ、攻撃が途切れないようにします
+func (g *Game) evolveWeapons() {
+	for _, evo := range g.defs.Evolutions {
+		if g.Player.passive(evo.Passive) == nil {
+			continue
+		}
+		for i, w := range g.Player.Weapons {
+			if w.Params.WeaponType != evo.Weapon || w.Level < w.Params.MaxLevel() {
+				continue
+			}
+			params, _ := g.defs.Weapon(evo.Into)
+			evolved := newWeapon(params)
+			evolved.lastAttackTime = w.lastAttackTime
+			evolved.Direction = w.Direction
+			evolved.Projectiles = w.Projectiles
+			g.Player.Weapons[i] = evolved
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package world
+
+import "math"
+
+// アイテムの種類
+type PickupKind string
+
+const (
+	PickupExp    PickupKind = "exp"    // 経験値の宝石
+	PickupHeal   PickupKind = "heal"   // 回復アイテム
+	PickupMagnet PickupKind = "magnet" // すべての宝石を引き寄せる磁石
+)
+
+// Pickup は敵が落としたアイテムです
+type Pickup struct {
+	X, Y      float64
+	Kind      PickupKind
+	Value     int  // 経験値の量、または回復量
+	attracted bool // プレイヤーに引き寄せられているか
+}
+
+// PickupParams はアイテムのパラメータです
+type PickupParams struct {
+	AttractSpeed  float64    `json:"attract_speed"`  // 引き寄せられる速度（1ティックあたり）
+	CollectRadius float64    `json:"collect_radius"` // この距離まで近づくと拾う
+	Gems          []GemTier  `json:"gems"`
+	Heal          DropParams `json:"heal"`
+	Magnet        DropParams `json:"magnet"`
+}
+
+// GemTier は経験値の量ごとの宝石の見た目です
+type GemTier struct {
+	MinExp int   `json:"min_exp"` // この量以上の経験値の宝石に使う
+	Color  Color `json:"color"`
+}
+
+// DropParams は敵が倒れたときに落とすアイテムのパラメータです
+type DropParams struct {
+	Chance float64 `json:"chance"` // 落とす確率
+	Amount int     `json:"amount"` // 効果量（回復量など）
+	Color  Color   `json:"color"`
+}
+
+// PickupColor はアイテムの表示色を返します
+func (d *Definitions) PickupColor(p *Pickup) Color {
+	switch p.Kind {
+	case PickupHeal:
+		return d.Pickups.Heal.Color
+	case PickupMagnet:
+		return d.Pickups.Magnet.Color
+	}
+	c := d.Pickups.Gems[0].Color
+	for _, tier := range d.Pickups.Gems {
+		if p.Value >= tier.MinExp {
+			c = tier.Color
+		}
+	}
+	return c
+}
+
+// dropPickups は倒した敵の位置に経験値の宝石と、確率で回復アイテムや磁石を落とします
+func (g *Game) dropPickups(enemy *Enemy) {
+	params := g.defs.Pickups
+	if enemy.ExpValue > 0 {
+		g.Pickups = append(g.Pickups, &Pickup{X: enemy.X, Y: enemy.Y, Kind: PickupExp, Value: enemy.ExpValue})
+	}
+	luck := g.Player.Stat(StatLuck)
+	if g.rng.Float64() < params.Heal.Chance*luck {
+		g.Pickups = append(g.Pickups, &Pickup{X: enemy.X, Y: enemy.Y, Kind: PickupHeal, Value: params.Heal.Amount})
+	}
+	if g.rng.Float64() < params.Magnet.Chance*luck {
+		g.Pickups = append(g.Pickups, &Pickup{X: enemy.X, Y: enemy.Y, Kind: PickupMagnet})
+	}
+}
+
+// updatePickups は回収範囲に入ったアイテムをプレイヤーに引き寄せ、触れたものを拾います
+// 画面から遠く離れた宝石は1つにまとめ、経験値をその宝石に足します。遠くの回復アイテムと磁石は敵と同じように消します
+// フィールドには端がないので、こうしないと置いていったアイテムが増え続けます
+func (g *Game) updatePickups() {
+	params := g.defs.Pickups
+	remaining := g.Pickups[:0]
+	var far *Pickup // 遠くの宝石をまとめる宝石
+	for _, p := range g.Pickups {
+		if !p.attracted && !g.camera.Contains(p.X, p.Y, despawnMargin) {
+			switch {
+			case p.Kind != PickupExp:
+				continue
+			case far == nil:
+				far = p
+			default:
+				far.Value += p.Value
+				continue
+			}
+		}
+		dx := g.Player.X - p.X
+		dy := g.Player.Y - p.Y
+		dist := math.Sqrt(dx*dx + dy*dy)
+		if dist <= g.Player.PickupRadius {
+			p.attracted = true
+		}
+		if p.attracted && dist > 0 {
+			step := math.Min(params.AttractSpeed, dist)
+			p.X += dx / dist * step
+			p.Y += dy / dist * step
+			dist -= step
+		}
+		if dist <= params.CollectRadius {
+			g.collect(p)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	// 拾ったアイテムへの参照を残さない
+	clear(g.Pickups[len(remaining):])
+	g.Pickups = remaining
+}
+
+// collect はアイテムの効果を適用します
+func (g *Game) collect(p *Pickup) {
+	switch p.Kind {
+	case PickupExp:
+		if levelUps := g.Player.gainExp(p.Value, g.defs.Player.ExpCurve); levelUps > 0 {
+			g.levelUp(levelUps)
+		}
+	case PickupHeal:
+		g.Player.HP = min(g.Player.HP+p.Value, g.Player.MaxHP)
+	case PickupMagnet:
+		for _, other := range g.Pickups {
+			if other.Kind == PickupExp {
+				other.attracted = true
+			}
+		}
+	}
+}
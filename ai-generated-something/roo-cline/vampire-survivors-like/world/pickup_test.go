@@ -0,0 +1,95 @@
+package world
+
+import "testing"
+
+func TestPickupAttractedWithinRadius(t *testing.T) {
+	g := newTestGame()
+	near := &Pickup{X: g.Player.X + g.Player.PickupRadius - 1, Y: g.Player.Y, Kind: PickupExp, Value: 1}
+	far := &Pickup{X: g.Player.X + g.Player.PickupRadius + 100, Y: g.Player.Y, Kind: PickupExp, Value: 1}
+	g.Pickups = []*Pickup{near, far}
+
+	for i := 0; i < TicksPerSecond && g.Player.Exp == 0; i++ {
+		g.updatePickups()
+	}
+
+	if g.Player.Exp != 1 {
+		t.Errorf("Exp = %d, want 1; gem within pickup radius was not collected", g.Player.Exp)
+	}
+	if len(g.Pickups) != 1 || g.Pickups[0] != far {
+		t.Errorf("gem outside pickup radius should stay where it is")
+	}
+}
+
+func TestFarGemsAreMerged(t *testing.T) {
+	g := newTestGame()
+	x := g.Player.X + ScreenWidth/2 + despawnMargin + 100
+	near := &Pickup{X: g.Player.X + 200, Y: g.Player.Y, Kind: PickupExp, Value: 1}
+	g.Pickups = []*Pickup{
+		{X: x, Y: g.Player.Y, Kind: PickupExp, Value: 5},
+		near,
+		{X: g.Player.X, Y: g.Player.Y - ScreenHeight - despawnMargin, Kind: PickupExp, Value: 7},
+		{X: x, Y: g.Player.Y + 50, Kind: PickupHeal, Value: 20},
+		{X: x, Y: g.Player.Y - 50, Kind: PickupExp, Value: 30},
+	}
+
+	g.updatePickups()
+
+	// 遠くの宝石は最初の1つにまとまり、回復アイテムは消える
+	if len(g.Pickups) != 2 || g.Pickups[1] != near {
+		t.Fatalf("Pickups = %v, want the merged far gem and the near gem", g.Pickups)
+	}
+	far := g.Pickups[0]
+	if far.Kind != PickupExp || far.Value != 42 || far.X != x {
+		t.Errorf("far gem = %+v, want an exp gem at X %v worth 42", far, x)
+	}
+
+	// まとめた宝石を拾えば、まとめた分の経験値がすべて手に入る
+	g.Player.X, g.Player.Y = far.X, far.Y
+	g.camera = cameraAt(g.Player.X, g.Player.Y)
+	g.updatePickups()
+	if g.Player.Exp != 42 {
+		t.Errorf("Exp = %d after collecting the far gem, want 42", g.Player.Exp)
+	}
+}
+
+func TestPickupRadiusPassive(t *testing.T) {
+	g := newTestGame()
+	radius := g.Player.PickupRadius
+	g.PendingLevelUps = 1
+	g.applySkill(SkillOption{Type: SkillPassive, Passive: "attractorb"})
+	if g.Player.PickupRadius != radius+30 {
+		t.Errorf("PickupRadius = %v, want %v", g.Player.PickupRadius, radius+30)
+	}
+}
+
+func TestPickupHeal(t *testing.T) {
+	g := newTestGame()
+	g.Player.HP = g.Player.MaxHP - 5
+	g.Pickups = []*Pickup{{X: g.Player.X, Y: g.Player.Y, Kind: PickupHeal, Value: 20}}
+
+	g.updatePickups()
+
+	if g.Player.HP != g.Player.MaxHP {
+		t.Errorf("HP = %d, want %d; healing should not exceed MaxHP", g.Player.HP, g.Player.MaxHP)
+	}
+}
+
+func TestPickupMagnet(t *testing.T) {
+	g := newTestGame()
+	var gems []*Pickup
+	for i := 1; i <= 3; i++ {
+		gems = append(gems, &Pickup{X: g.Player.X + float64(i)*500, Y: g.Player.Y, Kind: PickupExp, Value: 10})
+	}
+	g.Pickups = append([]*Pickup{{X: g.Player.X, Y: g.Player.Y, Kind: PickupMagnet}}, gems...)
+
+	for i := 0; i < TicksPerSecond*10 && len(g.Pickups) > 0; i++ {
+		g.updatePickups()
+	}
+
+	if len(g.Pickups) != 0 {
+		t.Fatalf("%d gems were not pulled in by the magnet", len(g.Pickups))
+	}
+	if g.Player.Exp != 30 {
+		t.Errorf("Exp = %d, want 30", g.Player.Exp)
+	}
+}
@@ -0,0 +1,83 @@
+package world
+
+// Player はプレイヤーキャラクターを表す構造体です
+type Player struct {
+	X, Y           float64
+	Speed          float64 // 移動速度（補正後）
+	PickupRadius   float64 // この距離に入ったアイテムを引き寄せる（補正後）
+	HP             int
+	MaxHP          int // 最大HP（補正後）
+	Level          int
+	Exp            int
+	ExpToNextLevel int
+	Weapons        []*Weapon
+	Passives       []*PassiveItem
+
+	base      PlayerParams     // 補正前のステータス
+	permanent []Modifier       // 永続的な強化による補正
+	character CharacterParams  // キャラクターとそのパッシブ
+	stats     map[Stat]float64 // 補正後のステータス
+	regen     float64          // まだ回復していない端数のHP
+
+	invulnerable int // 無敵の残りティック数
+}
+
+// newPlayer はキャラクター ch のプレイヤーを作成します
+// キャラクターが決めていない武器やステータスは player の定義を使います
+func newPlayer(defs *Definitions, ch CharacterParams) *Player {
+	base := ch.Base(defs.Player)
+	weapon, _ := defs.Weapon(base.Weapon)
+	p := &Player{
+		X:              float64(ScreenWidth) / 2,
+		Y:              float64(ScreenHeight) / 2,
+		HP:             base.HP,
+		Level:          1,
+		Exp:            0,
+		ExpToNextLevel: base.ExpCurve.expToNextLevel(1),
+		Weapons:        []*Weapon{newWeapon(weapon)},
+		base:           base,
+		character:      ch,
+	}
+	p.recalcStats()
+	return p
+}
+
+// weapon は持っている武器を返します。持っていなければ nil を返します
+func (p *Player) weapon(id WeaponType) *Weapon {
+	for _, w := range p.Weapons {
+		if w.Params.WeaponType == id {
+			return w
+		}
+	}
+	return nil
+}
+
+// ExpCurve はレベルアップに必要な経験値の曲線です
+// レベル L から次のレベルまでに必要な経験値は、Table に L 番目の値があればその値、
+// なければ Base + Growth*(L-1) です
+type ExpCurve struct {
+	Base   int   `json:"base"`
+	Growth int   `json:"growth"`
+	Table  []int `json:"table"` // 序盤のレベルだけ個別に調整するための表
+}
+
+func (c ExpCurve) expToNextLevel(level int) int {
+	if level-1 < len(c.Table) {
+		return c.Table[level-1]
+	}
+	return c.Base + c.Growth*(level-1)
+}
+
+// gainExp は経験値を加え、上がったレベルの数を返します
+// 一度に複数のレベルが上がることもあり、余った経験値は次のレベルに持ち越します
+func (p *Player) gainExp(exp int, curve ExpCurve) int {
+	p.Exp += exp
+	levelUps := 0
+	for p.Exp >= p.ExpToNextLevel {
+		p.Exp -= p.ExpToNextLevel
+		p.Level++
+		p.ExpToNextLevel = curve.expToNextLevel(p.Level)
+		levelUps++
+	}
+	return levelUps
+}
@@ -0,0 +1,97 @@
+package world
+
+import "math"
+
+// 弾のデータ
+type Projectile struct {
+	X, Y      float64
+	Angle     float64
+	Speed     float64 // 1ティックあたりの移動距離
+	Damage    int
+	hitsLeft  int     // あと何体の敵に当たれるか
+	traveled  float64 // 発射されてからの移動距離
+	maxRange  float64 // この距離を移動すると消える
+	knockback float64 // 当たった敵を押し戻す距離
+	hits      []projectileHit
+}
+
+// projectileHit は弾が敵に最後に当たった時刻です
+type projectileHit struct {
+	enemy *Enemy
+	time  float64
+}
+
+// fire は (x, y) から angle の方向に params の弾を発射します
+func (w *Weapon) fire(params WeaponParams, x, y, angle float64) {
+	w.Projectiles = append(w.Projectiles, Projectile{
+		X:         x,
+		Y:         y,
+		Angle:     angle,
+		Speed:     params.ProjectileSpeed,
+		Damage:    params.AttackDamage,
+		hitsLeft:  1 + params.Pierce,
+		maxRange:  params.AttackRange,
+		knockback: params.Knockback,
+	})
+}
+
+// canHit は弾が now の時点で enemy に当たれるかを返します
+func (p *Projectile) canHit(enemy *Enemy, now, cooldown float64) bool {
+	for _, h := range p.hits {
+		if h.enemy == enemy {
+			return cooldown > 0 && now-h.time >= cooldown
+		}
+	}
+	return true
+}
+
+// recordHit は弾が now の時点で enemy に当たったことを記録します
+func (p *Projectile) recordHit(enemy *Enemy, now float64) {
+	p.hitsLeft--
+	for i := range p.hits {
+		if p.hits[i].enemy == enemy {
+			p.hits[i].time = now
+			return
+		}
+	}
+	p.hits = append(p.hits, projectileHit{enemy: enemy, time: now})
+}
+
+// updateProjectiles は武器の弾を1ティック分動かし、敵との当たり判定を行います
+// 射程の端まで進んだ弾と、貫通できる数を使い切った弾は消えます
+func (g *Game) updateProjectiles(weapon *Weapon) {
+	now := g.clock.Now()
+	weaponType := weapon.Params.WeaponType
+
+	remaining := weapon.Projectiles[:0]
+	for _, proj := range weapon.Projectiles {
+		// 弾の移動
+		proj.X += math.Cos(proj.Angle) * proj.Speed
+		proj.Y += math.Sin(proj.Angle) * proj.Speed
+		proj.traveled += proj.Speed
+		if proj.traveled > proj.maxRange {
+			continue
+		}
+
+		// 敵との当たり判定
+		g.grid.query(proj.X, proj.Y, g.grid.maxSize/2, func(enemy *Enemy) {
+			if proj.hitsLeft <= 0 || !proj.canHit(enemy, now, weapon.Params.HitCooldown) {
+				return
+			}
+			dx := enemy.X - proj.X
+			dy := enemy.Y - proj.Y
+			dist := math.Sqrt(dx*dx + dy*dy)
+			if dist < enemy.Size/2 {
+				g.damageEnemy(enemy, proj.Damage, weaponType)
+				enemy.knockback(math.Cos(proj.Angle), math.Sin(proj.Angle), proj.knockback)
+				proj.recordHit(enemy, now)
+			}
+		})
+		if proj.hitsLeft <= 0 {
+			continue
+		}
+
+		remaining = append(remaining, proj)
+	}
+	weapon.Projectiles = remaining
+}
@@ -0,0 +1,148 @@
+package world
+
+import "testing"
+
+// newTestWeapon は右向きに弾を撃つ武器を作成します
+func newTestWeapon(pierce int, hitCooldown float64) *Weapon {
+	return newWeapon(WeaponParams{
+		WeaponType:      WeaponRanged,
+		Behavior:        BehaviorRanged,
+		AttackInterval:  1,
+		AttackRange:     100,
+		AttackDamage:    1,
+		ProjectileSpeed: 5,
+		Pierce:          pierce,
+		HitCooldown:     hitCooldown,
+	})
+}
+
+func TestProjectileMovesEveryTick(t *testing.T) {
+	g := newTestGame()
+	weapon := newTestWeapon(0, 0)
+	weapon.fire(weapon.Params, 0, 0, 0)
+
+	for i := 0; i < 3; i++ {
+		g.updateProjectiles(weapon)
+	}
+	if got := weapon.Projectiles[0].X; got != 15 {
+		t.Errorf("X after 3 ticks = %v, want 15", got)
+	}
+}
+
+func TestProjectileMaxRange(t *testing.T) {
+	g := newTestGame()
+	weapon := newTestWeapon(0, 0)
+	weapon.fire(weapon.Params, 0, 0, 0)
+
+	// 射程 100 を速度 5 で進むので 20 ティック目までは残る
+	for i := 0; i < 20; i++ {
+		g.updateProjectiles(weapon)
+	}
+	if len(weapon.Projectiles) != 1 {
+		t.Fatalf("projectile disappeared before reaching its range")
+	}
+	g.updateProjectiles(weapon)
+	if len(weapon.Projectiles) != 0 {
+		t.Errorf("projectile still alive after traveling beyond its range")
+	}
+}
+
+func TestProjectileRemovedOnImpact(t *testing.T) {
+	g := newTestGame()
+	weapon := newTestWeapon(0, 0)
+	first := placeEnemy(g, EnemyTank, 5, 0)
+	second := placeEnemy(g, EnemyTank, 10, 0)
+	weapon.fire(weapon.Params, g.Player.X, g.Player.Y, 0)
+
+	g.updateProjectiles(weapon)
+	g.updateProjectiles(weapon)
+
+	if len(weapon.Projectiles) != 0 {
+		t.Fatal("projectile survived an impact without pierce")
+	}
+	if damaged := (first.MaxHP - first.HP) + (second.MaxHP - second.HP); damaged != 1 {
+		t.Errorf("total damage = %d, want 1", damaged)
+	}
+}
+
+func TestProjectilePierce(t *testing.T) {
+	g := newTestGame()
+	weapon := newTestWeapon(2, 0)
+	var enemies []*Enemy
+	for i := 1; i <= 4; i++ {
+		enemies = append(enemies, placeEnemy(g, EnemyTank, float64(i*20), 0))
+	}
+	weapon.fire(weapon.Params, g.Player.X, g.Player.Y, 0)
+
+	for i := 0; i < 20 && len(weapon.Projectiles) > 0; i++ {
+		g.updateProjectiles(weapon)
+	}
+
+	if len(weapon.Projectiles) != 0 {
+		t.Fatal("projectile survived after using up its pierce")
+	}
+	for i, e := range enemies {
+		want := e.MaxHP
+		if i < 3 {
+			want--
+		}
+		if e.HP != want {
+			t.Errorf("enemies[%d].HP = %d, want %d", i, e.HP, want)
+		}
+	}
+}
+
+func TestProjectileHitsSameEnemyOnce(t *testing.T) {
+	g := newTestGame()
+	weapon := newTestWeapon(10, 0)
+	enemy := placeEnemy(g, EnemyBoss, 20, 0)
+	weapon.fire(weapon.Params, g.Player.X, g.Player.Y, 0)
+
+	// ボスの中を通り抜ける間も1度しか当たらない
+	for i := 0; i < 15; i++ {
+		g.updateProjectiles(weapon)
+	}
+	if got := enemy.MaxHP - enemy.HP; got != 1 {
+		t.Errorf("damage = %d, want 1", got)
+	}
+}
+
+func TestProjectileHitCooldown(t *testing.T) {
+	g := newTestGame()
+	weapon := newTestWeapon(10, 0.1)
+	enemy := placeEnemy(g, EnemyBoss, 0, 0)
+	weapon.Projectiles = append(weapon.Projectiles, Projectile{
+		X:        g.Player.X,
+		Y:        g.Player.Y,
+		Damage:   1,
+		hitsLeft: 11,
+		maxRange: 100,
+	})
+
+	// 止まっている弾は 0.1 秒ごとに同じ敵に当たる
+	for i := 0; i < TicksPerSecond/2; i++ {
+		g.clock.Tick()
+		g.updateProjectiles(weapon)
+	}
+	if got := enemy.MaxHP - enemy.HP; got != 5 {
+		t.Errorf("damage in 0.5s = %d, want 5", got)
+	}
+}
+
+func TestProjectileIgnoresDeadEnemies(t *testing.T) {
+	g := newTestGame()
+	weapon := newTestWeapon(0, 0)
+	dead := placeEnemy(g, EnemyNormal, 5, 0)
+	g.damageEnemy(dead, dead.HP, WeaponMelee)
+	score := g.Score
+	weapon.fire(weapon.Params, g.Player.X, g.Player.Y, 0)
+
+	g.updateProjectiles(weapon)
+
+	if len(weapon.Projectiles) != 1 {
+		t.Error("projectile was consumed by a dead enemy")
+	}
+	if g.Score != score {
+		t.Errorf("Score = %d, want %d; dead enemy was scored twice", g.Score, score)
+	}
+}
@@ -0,0 +1,278 @@
+package world
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+)
+
+// リプレイファイルの形式
+//
+//	"VSRP" | バージョン(1バイト) | シード(varint) | 定義の Hash(8バイト)
+//	| 強化の数(uvarint) | (id の長さ(uvarint) id レベル(uvarint))...
+//	| キャラクターの id の長さ(uvarint) | キャラクターの id | ステージの id の長さ(uvarint) | ステージの id
+//	| (入力(3バイト) 連続回数(uvarint))...
+//
+// 同じ入力が続くことが多いため、ランレングスで圧縮して保存します
+// バージョン5以前のリプレイは同じ展開にならないため読み込めません
+// （バージョン1は入力が1バイトで斜めの移動が速かった頃、バージョン2は強化が、バージョン3はキャラクターがなかった頃、
+// バージョン4はスキルの選択肢に重みがなかった頃、バージョン5はステージと定義を記録していなかった頃、
+// バージョン6は無敵の時間が1ティック短かった頃）
+const (
+	replayMagic   = "VSRP"
+	replayVersion = 7
+)
+
+// ErrInvalidReplay はリプレイファイルの形式が不正な場合のエラーです
+var ErrInvalidReplay = errors.New("invalid replay file")
+
+// ErrDefinitionsMismatch はリプレイを記録したときと定義が違う場合のエラーです
+var ErrDefinitionsMismatch = errors.New("replay was recorded with different definitions")
+
+// replayRun は同じ入力が続いた回数です
+type replayRun struct {
+	input encodedInput
+	count uint64
+}
+
+// Replay はシードと毎ティックの入力の記録です
+type Replay struct {
+	Seed      int64
+	DefsHash  uint64              // 記録したときの定義の Hash
+	Upgrades  map[UpgradeType]int // 記録を始めたときの永続的な強化のレベル
+	Character CharacterType       // 記録を始めたときのキャラクター
+	Stage     string              // 記録したときのステージの id
+	runs      []replayRun
+}
+
+// Config はリプレイを記録したときと同じゲームを作る設定を返します
+// defs が記録したときの定義と違えば同じ展開にならないため、ErrDefinitionsMismatch を返します
+func (r *Replay) Config(defs *Definitions) (Config, error) {
+	if defs == nil {
+		defs = DefaultDefinitions()
+	}
+	if defs.Hash() != r.DefsHash {
+		return Config{}, ErrDefinitionsMismatch
+	}
+	return Config{Seed: r.Seed, Defs: defs, Stage: r.Stage, Upgrades: maps.Clone(r.Upgrades), Character: r.Character}, nil
+}
+
+// Ticks は記録されているティック数を返します
+func (r *Replay) Ticks() uint64 {
+	var n uint64
+	for _, run := range r.runs {
+		n += run.count
+	}
+	return n
+}
+
+// WriteTo はリプレイを w に書き込みます
+func (r *Replay) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, 0, len(replayMagic)+1+binary.MaxVarintLen64+len(r.runs)*(len(encodedInput{})+binary.MaxVarintLen64))
+	buf = append(buf, replayMagic...)
+	buf = append(buf, replayVersion)
+	buf = binary.AppendVarint(buf, r.Seed)
+	buf = binary.BigEndian.AppendUint64(buf, r.DefsHash)
+	buf = binary.AppendUvarint(buf, uint64(len(r.Upgrades)))
+	for _, id := range slices.Sorted(maps.Keys(r.Upgrades)) {
+		buf = appendString(buf, string(id))
+		buf = binary.AppendUvarint(buf, uint64(r.Upgrades[id]))
+	}
+	buf = appendString(buf, string(r.Character))
+	buf = appendString(buf, r.Stage)
+	for _, run := range r.runs {
+		buf = append(buf, run.input[:]...)
+		buf = binary.AppendUvarint(buf, run.count)
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadReplay は r からリプレイを読み込みます
+func ReadReplay(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(replayMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReplay, err)
+	}
+	if string(header[:len(replayMagic)]) != replayMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidReplay)
+	}
+	if v := header[len(replayMagic)]; v != replayVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidReplay, v)
+	}
+
+	seed, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: seed: %v", ErrInvalidReplay, err)
+	}
+
+	var hash [8]byte
+	if _, err := io.ReadFull(br, hash[:]); err != nil {
+		return nil, fmt.Errorf("%w: definitions hash: %v", ErrInvalidReplay, err)
+	}
+
+	replay := &Replay{Seed: seed, DefsHash: binary.BigEndian.Uint64(hash[:])}
+	if replay.Upgrades, err = readUpgrades(br); err != nil {
+		return nil, fmt.Errorf("%w: upgrades: %v", ErrInvalidReplay, err)
+	}
+	character, err := readString(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: character: %v", ErrInvalidReplay, err)
+	}
+	replay.Character = CharacterType(character)
+	if replay.Stage, err = readString(br); err != nil {
+		return nil, fmt.Errorf("%w: stage: %v", ErrInvalidReplay, err)
+	}
+	for {
+		var input encodedInput
+		if _, err := io.ReadFull(br, input[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: input: %v", ErrInvalidReplay, err)
+		}
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: run length: %v", ErrInvalidReplay, err)
+		}
+		replay.runs = append(replay.runs, replayRun{input: input, count: count})
+	}
+	return replay, nil
+}
+
+// readUpgrades はリプレイのヘッダーから強化のレベルを読み込みます
+func readUpgrades(br *bufio.Reader) (map[UpgradeType]int, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	upgrades := make(map[UpgradeType]int)
+	for range n {
+		id, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		level, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		upgrades[UpgradeType(id)] = int(level)
+	}
+	return upgrades, nil
+}
+
+// appendString は長さ(uvarint)に続けて s を buf に追加します
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// readString は appendString で書いた文字列を読み込みます
+func readString(br *bufio.Reader) (string, error) {
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+	if size > 256 {
+		return "", errors.New("id too long")
+	}
+	s := make([]byte, size)
+	if _, err := io.ReadFull(br, s); err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// LoadReplay はファイルからリプレイを読み込みます
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+// Save はリプレイをファイルに保存します
+func (r *Replay) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReplayRecorder は毎ティックの入力をリプレイに記録します
+type ReplayRecorder struct {
+	replay *Replay
+}
+
+// NewReplayRecorder は cfg のシード・定義・ステージ・強化・キャラクターで始まるゲームの記録を開始します
+func NewReplayRecorder(cfg Config) *ReplayRecorder {
+	if cfg.Defs == nil {
+		cfg.Defs = DefaultDefinitions()
+	}
+	// ステージを省略したときは、再生時に既定のステージが変わっても同じステージになるよう id を記録する
+	stage, ok := cfg.Defs.Stage(cfg.Stage)
+	if !ok {
+		stage = cfg.Defs.Stages[0]
+	}
+	return &ReplayRecorder{replay: &Replay{
+		Seed:      cfg.Seed,
+		DefsHash:  cfg.Defs.Hash(),
+		Upgrades:  maps.Clone(cfg.Upgrades),
+		Character: cfg.Character,
+		Stage:     stage.ID,
+	}}
+}
+
+// Record は1ティック分の入力を記録します
+func (r *ReplayRecorder) Record(in Input) {
+	b := in.encode()
+	runs := r.replay.runs
+	if len(runs) > 0 && runs[len(runs)-1].input == b {
+		runs[len(runs)-1].count++
+		return
+	}
+	r.replay.runs = append(runs, replayRun{input: b, count: 1})
+}
+
+// Replay は記録したリプレイを返します
+func (r *ReplayRecorder) Replay() *Replay {
+	return r.replay
+}
+
+// ReplayInput はリプレイから毎ティックの入力を再生する InputSource です
+type ReplayInput struct {
+	runs []replayRun
+	pos  int    // 再生中の runs のインデックス
+	used uint64 // runs[pos] のうち再生済みの回数
+}
+
+// NewReplayInput はリプレイを先頭から再生する InputSource を作成します
+func NewReplayInput(r *Replay) *ReplayInput {
+	return &ReplayInput{runs: r.runs}
+}
+
+// Next は次のティックの入力を返します
+func (p *ReplayInput) Next() (Input, bool) {
+	for p.pos < len(p.runs) && p.used >= p.runs[p.pos].count {
+		p.pos++
+		p.used = 0
+	}
+	if p.pos >= len(p.runs) {
+		return Input{}, false
+	}
+	p.used++
+	return decodeInput(p.runs[p.pos].input), true
+}
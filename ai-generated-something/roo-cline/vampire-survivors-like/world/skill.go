@@ -0,0 +1,251 @@
+package world
+
+// 1回のレベルアップで提示するスキルの数
+const skillChoices = 3
+
+// スキルの種類
+type SkillType string
+
+const (
+	SkillNewWeapon     SkillType = "new_weapon"
+	SkillWeaponUpgrade SkillType = "weapon_upgrade"
+	SkillPassive       SkillType = "passive"
+)
+
+func (t SkillType) valid() bool {
+	switch t {
+	case SkillNewWeapon, SkillWeaponUpgrade, SkillPassive:
+		return true
+	}
+	return false
+}
+
+// スキル選択肢
+// 定義ファイルのスキルは、武器やパッシブアイテムごとに別の選択肢になります
+type SkillOption struct {
+	Type        SkillType   `json:"type"`
+	Description string      `json:"description"`
+	Weight      int         `json:"weight"` // 選択肢1つあたりの出やすさの重み
+	Weapon      WeaponType  `json:"-"`      // 獲得する武器（new_weapon）・強化する武器（weapon_upgrade）
+	Passive     PassiveType `json:"-"`      // 取得・強化するパッシブアイテム（passive）
+	Level       int         `json:"-"`      // 選んだあとの武器やパッシブアイテムのレベル
+}
+
+// skillKey は除外した選択肢を覚えておくためのキーです
+// 除外した武器の強化やパッシブアイテムは、レベルに関係なく二度と出ません
+type skillKey struct {
+	Type    SkillType
+	Weapon  WeaponType
+	Passive PassiveType
+}
+
+func (o SkillOption) key() skillKey {
+	return skillKey{Type: o.Type, Weapon: o.Weapon, Passive: o.Passive}
+}
+
+// LevelUpParams はレベルアップ時のスキル選択の設定です
+type LevelUpParams struct {
+	Rerolls  int `json:"rerolls"`  // 1回のプレイで選択肢を引き直せる回数
+	Skips    int `json:"skips"`    // 1回のプレイでスキルを選ばずに飛ばせる回数
+	Banishes int `json:"banishes"` // 1回のプレイで選択肢を除外できる回数
+}
+
+// SkillActions はスキル選択で使える操作の残りの回数です
+type SkillActions struct {
+	Rerolls  int
+	Skips    int
+	Banishes int
+}
+
+func (p LevelUpParams) actions() SkillActions {
+	return SkillActions{Rerolls: p.Rerolls, Skips: p.Skips, Banishes: p.Banishes}
+}
+
+// levelUp は levelUps 回分のスキル選択を積みます
+// スキルは1レベルにつき1回ずつ順番に選びます
+func (g *Game) levelUp(levelUps int) {
+	g.emit(Event{Kind: EventLevelUp})
+	// レベルに応じたキャラクターのパッシブを反映する
+	g.Player.recalcStats()
+	g.PendingLevelUps += levelUps
+	if !g.ChoosingSkill {
+		g.ChoosingSkill = true
+		g.generateSkillOptions()
+	}
+}
+
+// skillCandidates は今選べるスキルの一覧を返します
+// 武器の獲得・武器の強化・パッシブアイテムは、対象ごとに別の選択肢になります
+// 選んでも何も起きない選択肢（最大レベルの強化や、持てる数を超える武器）と除外した選択肢は含みません
+func (g *Game) skillCandidates() []SkillOption {
+	var candidates []SkillOption
+	add := func(option SkillOption) {
+		if !g.banished[option.key()] {
+			candidates = append(candidates, option)
+		}
+	}
+	for _, skill := range g.defs.Skills {
+		switch skill.Type {
+		case SkillNewWeapon:
+			if len(g.Player.Weapons) >= maxWeapons {
+				continue
+			}
+			for _, w := range g.rewardWeapons() {
+				option := skill
+				option.Weapon = w.WeaponType
+				option.Level = 1
+				add(option)
+			}
+		case SkillWeaponUpgrade:
+			for _, w := range g.Player.Weapons {
+				if w.Level >= w.Params.MaxLevel() {
+					continue
+				}
+				option := skill
+				option.Weapon = w.Params.WeaponType
+				option.Level = w.Level + 1
+				add(option)
+			}
+		case SkillPassive:
+			for _, p := range g.defs.Passives {
+				level := 0
+				if item := g.Player.passive(p.PassiveType); item != nil {
+					level = item.Level
+				}
+				if level >= p.MaxLevel {
+					continue
+				}
+				option := skill
+				option.Passive = p.PassiveType
+				option.Level = level + 1
+				add(option)
+			}
+		}
+	}
+	return candidates
+}
+
+// generateSkillOptions は重みに応じて選択肢を重複なく選びます
+// 選べるスキルがひとつもなければ、残りのレベルアップは選ばずに終えます
+func (g *Game) generateSkillOptions() {
+	candidates := g.skillCandidates()
+	if len(candidates) == 0 {
+		g.SkillOptions = nil
+		g.PendingLevelUps = 0
+		g.ChoosingSkill = false
+		return
+	}
+	g.SkillOptions = make([]SkillOption, min(skillChoices, len(candidates)))
+
+	total := 0
+	for _, c := range candidates {
+		total += c.Weight
+	}
+	for i := range g.SkillOptions {
+		n := g.rng.Intn(total)
+		idx := 0
+		for n >= candidates[idx].Weight {
+			n -= candidates[idx].Weight
+			idx++
+		}
+		g.SkillOptions[i] = candidates[idx]
+		total -= candidates[idx].Weight
+		candidates = append(candidates[:idx], candidates[idx+1:]...)
+	}
+}
+
+// rewardWeapons は新しい武器として獲得できる武器の一覧を返します
+// 進化でのみ手に入る武器と、すでに持っている武器（進化させたものを含む）は含みません
+func (g *Game) rewardWeapons() []WeaponParams {
+	owned := make(map[WeaponType]bool)
+	for _, w := range g.Player.Weapons {
+		owned[w.Params.WeaponType] = true
+	}
+	for _, evo := range g.defs.Evolutions {
+		if owned[evo.Into] {
+			owned[evo.Weapon] = true
+		}
+	}
+	var weapons []WeaponParams
+	for _, w := range g.defs.Weapons {
+		if w.Evolution || owned[w.WeaponType] {
+			continue
+		}
+		weapons = append(weapons, w)
+	}
+	return weapons
+}
+
+// chooseSkill はスキル選択中の入力を処理します
+// 選択肢の番号が範囲外のときや、回数の残っていない操作は無視します
+func (g *Game) chooseSkill(in Input) {
+	switch in.SkillAction {
+	case SkillActionReroll:
+		if g.SkillActions.Rerolls > 0 {
+			g.SkillActions.Rerolls--
+			g.generateSkillOptions()
+		}
+	case SkillActionSkip:
+		if g.SkillActions.Skips > 0 {
+			g.SkillActions.Skips--
+			g.nextSkillChoice()
+		}
+	case SkillActionBanish:
+		if g.SkillActions.Banishes > 0 && in.Choice > 0 && in.Choice <= len(g.SkillOptions) {
+			g.SkillActions.Banishes--
+			g.banished[g.SkillOptions[in.Choice-1].key()] = true
+			g.generateSkillOptions()
+		}
+	default:
+		if in.Choice > 0 && in.Choice <= len(g.SkillOptions) {
+			g.applySkill(g.SkillOptions[in.Choice-1])
+		}
+	}
+}
+
+func (g *Game) applySkill(skill SkillOption) {
+	switch skill.Type {
+	case SkillNewWeapon:
+		if params, ok := g.defs.Weapon(skill.Weapon); ok && len(g.Player.Weapons) < maxWeapons && g.Player.weapon(skill.Weapon) == nil {
+			g.Player.Weapons = append(g.Player.Weapons, newWeapon(params))
+		}
+	case SkillWeaponUpgrade:
+		if w := g.Player.weapon(skill.Weapon); w != nil {
+			w.levelUp()
+		}
+	case SkillPassive:
+		g.acquirePassive(skill.Passive)
+	}
+	g.evolveWeapons()
+	g.nextSkillChoice()
+}
+
+// nextSkillChoice は今のレベルアップのスキル選択を終えます
+// まだ選んでいないレベルアップがあれば続けて選びます
+func (g *Game) nextSkillChoice() {
+	g.PendingLevelUps--
+	if g.PendingLevelUps > 0 {
+		g.generateSkillOptions()
+		return
+	}
+	g.SkillOptions = nil
+	g.ChoosingSkill = false
+}
+
+// acquirePassive はパッシブアイテムを取得するか、持っていればレベルを上げます
+func (g *Game) acquirePassive(id PassiveType) {
+	params, ok := g.defs.Passive(id)
+	if !ok {
+		return
+	}
+	item := g.Player.passive(id)
+	if item == nil {
+		item = &PassiveItem{Params: params}
+		g.Player.Passives = append(g.Player.Passives, item)
+	}
+	if item.Level >= params.MaxLevel {
+		return
+	}
+	item.Level++
+	g.Player.recalcStats()
+}
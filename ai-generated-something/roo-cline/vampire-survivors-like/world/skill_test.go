@@ -0,0 +1,117 @@
+package world
+
+import "testing"
+
+// startSkillChoice はレベルアップしてスキル選択を始めます
+func startSkillChoice(t *testing.T, g *Game, levelUps int) {
+	t.Helper()
+	g.levelUp(levelUps)
+	if !g.ChoosingSkill || len(g.SkillOptions) != skillChoices {
+		t.Fatalf("ChoosingSkill, len(SkillOptions) = %v, %d, want true, %d", g.ChoosingSkill, len(g.SkillOptions), skillChoices)
+	}
+}
+
+func TestSkillChoiceOutOfRangeIsIgnored(t *testing.T) {
+	g := newTestGame()
+	startSkillChoice(t, g, 1)
+	options := g.SkillOptions
+
+	g.Step(Input{Choice: skillChoices + 1})
+	if !g.ChoosingSkill || &g.SkillOptions[0] != &options[0] {
+		t.Fatal("an out of range choice changed the skill choice")
+	}
+	g.Step(Input{Choice: skillChoices})
+	if g.ChoosingSkill {
+		t.Error("ChoosingSkill after choosing, want false")
+	}
+}
+
+func TestSkillActionsAreLimited(t *testing.T) {
+	g := newTestGame()
+	limits := g.defs.LevelUp
+	startSkillChoice(t, g, limits.Skips+1)
+
+	for i := 0; i < limits.Rerolls+1; i++ {
+		g.Step(Input{SkillAction: SkillActionReroll})
+	}
+	if g.SkillActions.Rerolls != 0 || !g.ChoosingSkill {
+		t.Errorf("Rerolls, ChoosingSkill = %d, %v after rerolling, want 0, true", g.SkillActions.Rerolls, g.ChoosingSkill)
+	}
+
+	// 飛ばせる回数を使い切ったら、スキルを選ぶしかない
+	for i := 0; i < limits.Skips+1; i++ {
+		g.Step(Input{SkillAction: SkillActionSkip})
+	}
+	if g.SkillActions.Skips != 0 || g.PendingLevelUps != 1 || !g.ChoosingSkill {
+		t.Errorf("Skips, PendingLevelUps, ChoosingSkill = %d, %d, %v, want 0, 1, true",
+			g.SkillActions.Skips, g.PendingLevelUps, g.ChoosingSkill)
+	}
+
+	// リスタートすると回数は戻る
+	g.GameOver = true
+	g.Step(Input{Restart: true})
+	if g.SkillActions != limits.actions() {
+		t.Errorf("SkillActions after restart = %+v, want %+v", g.SkillActions, limits.actions())
+	}
+}
+
+func TestBanishedSkillIsNotOfferedAgain(t *testing.T) {
+	g := newTestGame()
+	startSkillChoice(t, g, 1)
+	banished := g.SkillOptions[0].key()
+
+	g.Step(Input{SkillAction: SkillActionBanish, Choice: 1})
+	if g.SkillActions.Banishes != g.defs.LevelUp.Banishes-1 || !g.ChoosingSkill {
+		t.Fatalf("Banishes, ChoosingSkill = %d, %v, want one used and still choosing", g.SkillActions.Banishes, g.ChoosingSkill)
+	}
+	for _, c := range g.skillCandidates() {
+		if c.key() == banished {
+			t.Fatalf("banished option %+v is still a candidate", c)
+		}
+	}
+
+	// 選択肢の番号がなければ除外しない
+	g.Step(Input{SkillAction: SkillActionBanish})
+	if g.SkillActions.Banishes != g.defs.LevelUp.Banishes-1 {
+		t.Errorf("Banishes = %d after banishing nothing", g.SkillActions.Banishes)
+	}
+}
+
+func TestSkillWeights(t *testing.T) {
+	defs := *DefaultDefinitions()
+	defs.Skills = []SkillOption{
+		{Type: SkillNewWeapon, Description: "new", Weight: 1000},
+		{Type: SkillPassive, Description: "passive", Weight: 1},
+	}
+	g := NewGameWithConfig(Config{Seed: 1, Defs: &defs})
+	startSkillChoice(t, g, 1)
+
+	// 新しい武器の選択肢は3つしかないので、重みが大きければほとんど毎回すべて並ぶ
+	newWeapons := 0
+	for range 100 {
+		g.generateSkillOptions()
+		for _, o := range g.SkillOptions {
+			if o.Type == SkillNewWeapon {
+				newWeapons++
+			}
+		}
+	}
+	if newWeapons < 290 {
+		t.Errorf("new weapon offered %d times out of 300, want almost always", newWeapons)
+	}
+}
+
+func TestNoSkillCandidatesEndsChoice(t *testing.T) {
+	defs := *DefaultDefinitions()
+	defs.Skills = []SkillOption{{Type: SkillWeaponUpgrade, Description: "upgrade", Weight: 1}}
+	g := NewGameWithConfig(Config{Seed: 1, Defs: &defs})
+	melee := g.Player.Weapons[0]
+	for melee.Level < melee.Params.MaxLevel() {
+		melee.levelUp()
+	}
+
+	g.levelUp(2)
+	if g.ChoosingSkill || g.PendingLevelUps != 0 {
+		t.Errorf("ChoosingSkill, PendingLevelUps = %v, %d with nothing to offer, want false, 0", g.ChoosingSkill, g.PendingLevelUps)
+	}
+}
@@ -0,0 +1,162 @@
+package world
+
+import "math"
+
+// ステータスの種類
+type Stat string
+
+const (
+	StatMight           Stat = "might"            // 攻撃力の倍率
+	StatCooldown        Stat = "cooldown"         // 攻撃間隔の倍率
+	StatArea            Stat = "area"             // 攻撃範囲の倍率
+	StatProjectileSpeed Stat = "projectile_speed" // 弾の速度の倍率
+	StatArmor           Stat = "armor"            // 受けるダメージの軽減量
+	StatRegen           Stat = "regen"            // 1秒あたりのHP回復量
+	StatLuck            Stat = "luck"             // アイテムを落とす確率の倍率
+	StatPickupRadius    Stat = "pickup_radius"    // アイテムを引き寄せる範囲
+	StatMaxHP           Stat = "max_hp"           // 最大HP
+	StatMoveSpeed       Stat = "move_speed"       // 移動速度
+)
+
+// AllStats はすべてのステータスの種類です
+var AllStats = []Stat{
+	StatMight, StatCooldown, StatArea, StatProjectileSpeed, StatArmor,
+	StatRegen, StatLuck, StatPickupRadius, StatMaxHP, StatMoveSpeed,
+}
+
+func (s Stat) valid() bool {
+	for _, stat := range AllStats {
+		if s == stat {
+			return true
+		}
+	}
+	return false
+}
+
+// 補正で下がる倍率の下限
+// 補正を重ねても攻撃間隔が0になったり、攻撃力が負になったりしないようにする
+const minStatMultiplier = 0.1
+
+// statMinimums は補正で下がるステータスの下限です
+// 最大HPが0になってすぐに倒れたり、防御力が負になって受けるダメージが増えたりしないようにする
+var statMinimums = map[Stat]float64{
+	StatMight:           minStatMultiplier,
+	StatCooldown:        minStatMultiplier,
+	StatArea:            minStatMultiplier,
+	StatProjectileSpeed: minStatMultiplier,
+	StatLuck:            minStatMultiplier,
+	StatArmor:           0,
+	StatRegen:           0,
+	StatPickupRadius:    0,
+	StatMaxHP:           1,
+	StatMoveSpeed:       0,
+}
+
+// Modifier はステータスの補正です
+// 補正後の値は (基本値 + Flat の合計) * (1 + Percent の合計 / 100) です
+// ただし (1 + Percent の合計 / 100) は minStatMultiplier を、補正後の値は statMinimums の下限を下回りません
+type Modifier struct {
+	Stat    Stat    `json:"stat"`
+	Flat    float64 `json:"flat"`
+	Percent float64 `json:"percent"`
+}
+
+// baseStats はプレイヤーの補正前のステータスを返します
+func baseStats(params PlayerParams) map[Stat]float64 {
+	return map[Stat]float64{
+		StatMight:           1,
+		StatCooldown:        1,
+		StatArea:            1,
+		StatProjectileSpeed: 1,
+		StatArmor:           params.Armor,
+		StatRegen:           0,
+		StatLuck:            1,
+		StatPickupRadius:    params.PickupRadius,
+		StatMaxHP:           float64(params.HP),
+		StatMoveSpeed:       params.Speed,
+	}
+}
+
+// Stat は補正を適用したステータスの値を返します
+func (p *Player) Stat(s Stat) float64 {
+	return p.stats[s]
+}
+
+// recalcStats は持っているパッシブアイテムの補正からステータスを計算し直します
+func (p *Player) recalcStats() {
+	flat := make(map[Stat]float64)
+	percent := make(map[Stat]float64)
+	for _, item := range p.Passives {
+		for _, m := range item.Params.Modifiers {
+			flat[m.Stat] += m.Flat * float64(item.Level)
+			percent[m.Stat] += m.Percent * float64(item.Level)
+		}
+	}
+	for _, m := range p.permanent {
+		flat[m.Stat] += m.Flat
+		percent[m.Stat] += m.Percent
+	}
+	for _, m := range p.character.Passive.Modifiers {
+		flat[m.Stat] += m.Flat
+		percent[m.Stat] += m.Percent
+	}
+	for _, m := range p.character.Passive.PerLevel {
+		flat[m.Stat] += m.Flat * float64(p.Level-1)
+		percent[m.Stat] += m.Percent * float64(p.Level-1)
+	}
+
+	p.stats = baseStats(p.base)
+	for _, s := range AllStats {
+		p.stats[s] = max((p.stats[s]+flat[s])*max(1+percent[s]/100, minStatMultiplier), statMinimums[s])
+	}
+
+	// よく使うステータスはフィールドにも反映する
+	maxHP := int(math.Round(p.stats[StatMaxHP]))
+	if maxHP > p.MaxHP {
+		// 最大HPが増えた分は現在のHPも回復する
+		p.HP += maxHP - p.MaxHP
+	}
+	p.MaxHP = maxHP
+	p.HP = min(p.HP, p.MaxHP)
+	p.Speed = p.stats[StatMoveSpeed]
+	p.PickupRadius = p.stats[StatPickupRadius]
+}
+
+// EffectiveWeapon はステータスの補正を適用した武器のパラメータを返します
+// 攻撃力が下がっても、ダメージのある武器は最低1のダメージを与えます
+func (p *Player) EffectiveWeapon(params WeaponParams) WeaponParams {
+	if params.AttackDamage > 0 {
+		params.AttackDamage = max(1, int(math.Round(float64(params.AttackDamage)*p.stats[StatMight])))
+	}
+	params.AttackInterval *= p.stats[StatCooldown]
+	params.AttackRange *= p.stats[StatArea]
+	params.ProjectileSpeed *= p.stats[StatProjectileSpeed]
+	return params
+}
+
+// regenerate は1ティック分のHPを回復します
+func (p *Player) regenerate() {
+	p.regen += p.stats[StatRegen] / TicksPerSecond
+	if p.regen >= 1 {
+		heal := int(p.regen)
+		p.regen -= float64(heal)
+		p.HP = min(p.HP+heal, p.MaxHP)
+	}
+}
+
+// damage は防御力で軽減したダメージを受けます
+// 防御力が高くても最低1のダメージは受け、そのあとしばらく無敵になります
+// 無敵の間はダメージを受けず、false を返します
+func (p *Player) damage(amount int) bool {
+	if p.invulnerable > 0 {
+		return false
+	}
+	p.HP -= max(1, amount-int(p.stats[StatArmor]))
+	p.invulnerable = ticks(p.base.Invulnerability)
+	return true
+}
+
+// Invulnerable はダメージを受けたあとの無敵の間かを返します
+func (p *Player) Invulnerable() bool {
+	return p.invulnerable > 0
+}
@@ -0,0 +1,119 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+func TestModifiersStack(t *testing.T) {
+	g := newTestGame()
+	g.Player.Passives = []*PassiveItem{
+		{Params: PassiveParams{Modifiers: []Modifier{{Stat: StatMight, Percent: 10}}}, Level: 2},
+		{Params: PassiveParams{Modifiers: []Modifier{{Stat: StatMight, Flat: 0.5}, {Stat: StatArea, Percent: 50}}}, Level: 1},
+	}
+	g.Player.recalcStats()
+
+	// (1 + 0.5) * (1 + 20%)
+	if got := g.Player.Stat(StatMight); math.Abs(got-1.8) > 1e-9 {
+		t.Errorf("Stat(might) = %v, want 1.8", got)
+	}
+
+	params := WeaponParams{AttackDamage: 10, AttackRange: 100, AttackInterval: 1, ProjectileSpeed: 2}
+	got := g.Player.EffectiveWeapon(params)
+	if got.AttackDamage != 18 || got.AttackRange != 150 || got.AttackInterval != 1 || got.ProjectileSpeed != 2 {
+		t.Errorf("EffectiveWeapon = %+v, want damage 18, range 150, interval 1, speed 2", got)
+	}
+}
+
+func TestStackedNegativeModifiersAreClamped(t *testing.T) {
+	g := newTestGame()
+	// パッシブアイテム・永続的な強化・キャラクターの補正を合わせて -100% を超える
+	g.Player.Passives = []*PassiveItem{
+		{Params: PassiveParams{Modifiers: []Modifier{{Stat: StatCooldown, Percent: -40}, {Stat: StatMight, Percent: -60}}}, Level: 2},
+	}
+	g.Player.permanent = []Modifier{{Stat: StatCooldown, Percent: -30}, {Stat: StatArea, Percent: -150}}
+	g.Player.character.Passive.Modifiers = []Modifier{
+		{Stat: StatMight, Flat: -2},
+		{Stat: StatProjectileSpeed, Percent: -200},
+		{Stat: StatLuck, Flat: -5},
+		// 固定値の補正も基本値を超えて下げる
+		{Stat: StatMaxHP, Flat: -1000},
+		{Stat: StatArmor, Flat: -5},
+		{Stat: StatRegen, Flat: -3},
+		{Stat: StatPickupRadius, Flat: -1000},
+		{Stat: StatMoveSpeed, Flat: -1000},
+	}
+	g.Player.recalcStats()
+
+	for _, s := range AllStats {
+		if got := g.Player.Stat(s); got != statMinimums[s] {
+			t.Errorf("Stat(%s) = %v, want the minimum %v", s, got, statMinimums[s])
+		}
+	}
+	if g.Player.MaxHP != 1 || g.Player.HP != 1 || g.Player.Speed != 0 || g.Player.PickupRadius != 0 {
+		t.Errorf("MaxHP, HP, Speed, PickupRadius = %d, %d, %v, %v, want 1, 1, 0, 0", g.Player.MaxHP, g.Player.HP, g.Player.Speed, g.Player.PickupRadius)
+	}
+
+	// 防御力が負にならないので、受けるダメージは増えない
+	hp := g.Player.MaxHP + 10
+	g.Player.HP = hp
+	g.Player.damage(3)
+	if g.Player.HP != hp-3 {
+		t.Errorf("HP = %d after taking 3 damage, want %d", g.Player.HP, hp-3)
+	}
+
+	// 攻撃力が 0.1 倍でも、攻撃力の低い武器のダメージは0にならない
+	for _, damage := range []int{1, 4, 10} {
+		params := WeaponParams{AttackDamage: damage, AttackRange: 100, AttackInterval: 1, ProjectileSpeed: 2}
+		got := g.Player.EffectiveWeapon(params)
+		if got.AttackInterval <= 0 || got.AttackDamage < 1 || got.AttackRange <= 0 {
+			t.Errorf("EffectiveWeapon(damage %d) = %+v, want a positive interval, damage and range", damage, got)
+		}
+	}
+}
+
+func TestMaxHPPassiveHeals(t *testing.T) {
+	g := newTestGame()
+	g.Player.HP = 50
+	maxHP := g.Player.MaxHP
+
+	g.PendingLevelUps = 1
+	g.applySkill(SkillOption{Type: SkillPassive, Passive: "hollow_heart"})
+
+	gain := g.Player.MaxHP - maxHP
+	if gain <= 0 {
+		t.Fatalf("MaxHP = %d, want more than %d", g.Player.MaxHP, maxHP)
+	}
+	if g.Player.HP != 50+gain {
+		t.Errorf("HP = %d, want %d", g.Player.HP, 50+gain)
+	}
+}
+
+func TestArmorAndRegen(t *testing.T) {
+	g := newTestGame()
+	g.Player.Passives = []*PassiveItem{{Params: PassiveParams{Modifiers: []Modifier{
+		{Stat: StatArmor, Flat: 3},
+		{Stat: StatRegen, Flat: 1},
+	}}, Level: 1}}
+	g.Player.recalcStats()
+
+	// 防御力が高くても最低1のダメージは受ける
+	hp := g.Player.HP
+	g.Player.damage(1)
+	if g.Player.HP != hp-1 {
+		t.Errorf("HP = %d after armored hit, want %d", g.Player.HP, hp-1)
+	}
+	g.Player.invulnerable = 0
+	g.Player.damage(5)
+	if g.Player.HP != hp-3 {
+		t.Errorf("HP = %d after armored hit, want %d", g.Player.HP, hp-3)
+	}
+
+	// 1秒で1回復する
+	for range TicksPerSecond {
+		g.Player.regenerate()
+	}
+	if g.Player.HP != hp-2 {
+		t.Errorf("HP = %d after regen, want %d", g.Player.HP, hp-2)
+	}
+}
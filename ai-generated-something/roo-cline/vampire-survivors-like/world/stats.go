@@ -0,0 +1,37 @@
+package world
+
+// Stats は1回のプレイの統計です
+type Stats struct {
+	SurvivalTime float64            `json:"survival_time"` // 生存時間（秒）
+	Level        int                `json:"level"`
+	Score        int                `json:"score"`
+	Kills        map[EnemyType]int  `json:"kills"`        // 敵の種類ごとの撃破数
+	DamageDealt  map[WeaponType]int `json:"damage_dealt"` // 武器の種類ごとの与ダメージ
+	Coins        int                `json:"coins"`        // このプレイで得たコイン
+}
+
+func newStats() Stats {
+	return Stats{
+		Kills:       make(map[EnemyType]int),
+		DamageDealt: make(map[WeaponType]int),
+	}
+}
+
+// Stats は現在までのプレイの統計を返します
+func (g *Game) Stats() Stats {
+	s := Stats{
+		SurvivalTime: g.clock.Now(),
+		Level:        g.Player.Level,
+		Score:        g.Score,
+		Kills:        make(map[EnemyType]int, len(g.stats.Kills)),
+		DamageDealt:  make(map[WeaponType]int, len(g.stats.DamageDealt)),
+	}
+	for k, v := range g.stats.Kills {
+		s.Kills[k] = v
+	}
+	for k, v := range g.stats.DamageDealt {
+		s.DamageDealt[k] = v
+	}
+	s.Coins = g.defs.Coins.coins(s)
+	return s
+}
@@ -0,0 +1,50 @@
+package world
+
+import "math"
+
+// UpgradeType は永続的な強化の種類です
+type UpgradeType string
+
+// UpgradeParams は永続的な強化の定義です
+// 強化はプレイで得たコインで買い、次のゲームから最初のステータスを補正します
+type UpgradeParams struct {
+	UpgradeType UpgradeType `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Modifiers   []Modifier  `json:"modifiers"` // 1レベルあたりのステータスの補正
+	MaxLevel    int         `json:"max_level"`
+	Cost        int         `json:"cost"` // 1レベル目の値段。n レベル目は n 倍になる
+}
+
+// Price は level レベル目を買う値段を返します
+func (u UpgradeParams) Price(level int) int {
+	return u.Cost * level
+}
+
+// CoinParams はプレイの結果から得られるコインの量です
+type CoinParams struct {
+	PerScore  float64 `json:"per_score"`  // スコア1あたり
+	PerMinute float64 `json:"per_minute"` // 生存時間1分あたり
+}
+
+// coins は統計 s のプレイで得られるコインの枚数を返します
+func (c CoinParams) coins(s Stats) int {
+	return int(math.Floor(float64(s.Score)*c.PerScore + s.SurvivalTime/60*c.PerMinute))
+}
+
+// upgradeModifiers は強化のレベルに応じたステータスの補正を返します
+// 定義にない強化は無視し、レベルは最大レベルまでに抑えます
+func upgradeModifiers(defs *Definitions, levels map[UpgradeType]int) []Modifier {
+	var mods []Modifier
+	// 補正の順番で結果が変わらないよう、定義の順に足す
+	for _, u := range defs.Upgrades {
+		level := min(levels[u.UpgradeType], u.MaxLevel)
+		if level <= 0 {
+			continue
+		}
+		for _, m := range u.Modifiers {
+			mods = append(mods, Modifier{Stat: m.Stat, Flat: m.Flat * float64(level), Percent: m.Percent * float64(level)})
+		}
+	}
+	return mods
+}
@@ -0,0 +1,49 @@
+package world
+
+import "testing"
+
+func TestUpgradesModifyStartingStats(t *testing.T) {
+	defs := DefaultDefinitions()
+	base := NewGameWithConfig(Config{Seed: 1, Defs: defs})
+	g := NewGameWithConfig(Config{Seed: 1, Defs: defs, Upgrades: map[UpgradeType]int{
+		"max_hp":  2,
+		"unknown": 3, // 定義にない強化は無視する
+	}})
+
+	maxHP, _ := defs.Upgrade("max_hp")
+	want := base.Player.MaxHP + 2*int(maxHP.Modifiers[0].Flat)
+	if g.Player.MaxHP != want || g.Player.HP != want {
+		t.Errorf("HP, MaxHP = %d, %d, want %d, %d", g.Player.HP, g.Player.MaxHP, want, want)
+	}
+
+	// 最大レベルを超えたレベルは最大レベルとして扱う
+	over := NewGameWithConfig(Config{Seed: 1, Defs: defs, Upgrades: map[UpgradeType]int{"max_hp": maxHP.MaxLevel + 10}})
+	if want := base.Player.MaxHP + maxHP.MaxLevel*int(maxHP.Modifiers[0].Flat); over.Player.MaxHP != want {
+		t.Errorf("MaxHP = %d above max level, want %d", over.Player.MaxHP, want)
+	}
+}
+
+func TestSetUpgradesAppliesOnRestart(t *testing.T) {
+	g := newTestGame()
+	hp := g.Player.MaxHP
+	g.SetUpgrades(map[UpgradeType]int{"max_hp": 1})
+	if g.Player.MaxHP != hp {
+		t.Fatal("SetUpgrades changed the current run")
+	}
+
+	g.GameOver = true
+	g.Step(Input{Restart: true})
+	if g.Player.MaxHP <= hp {
+		t.Errorf("MaxHP = %d after restart, want more than %d", g.Player.MaxHP, hp)
+	}
+}
+
+func TestCoins(t *testing.T) {
+	c := CoinParams{PerScore: 0.01, PerMinute: 2}
+	if got := c.coins(Stats{Score: 1550, SurvivalTime: 90}); got != 18 {
+		t.Errorf("coins = %d, want 18", got)
+	}
+	if got := newTestGame().Stats().Coins; got != 0 {
+		t.Errorf("Stats().Coins = %d at the start, want 0", got)
+	}
+}
@@ -0,0 +1,180 @@
+package world
+
+import "math"
+
+// 隊列の種類
+type Formation string
+
+const (
+	FormationRing  Formation = "ring"  // プレイヤーを囲む輪
+	FormationSwarm Formation = "swarm" // 画面の一辺からまとまって現れる群れ
+	FormationBoss  Formation = "boss"  // ボス（出現数の上限を無視する）
+)
+
+func (f Formation) valid() bool {
+	switch f {
+	case FormationRing, FormationSwarm, FormationBoss:
+		return true
+	}
+	return false
+}
+
+// StageParams はステージごとの敵の出現のタイムラインです
+type StageParams struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	MaxEnemies int         `json:"max_enemies"` // 同時に出現できる敵の数
+	Rate       []RatePoint `json:"rate"`        // 1秒あたりの出現数の推移
+	Bands      []SpawnBand `json:"bands"`       // 通常の出現で選ばれる敵
+	Events     []WaveEvent `json:"events"`      // 決まった時刻に起こる出現
+}
+
+// RatePoint は Time 秒の時点での1秒あたりの出現数です
+// 点と点の間は線形に補間し、最後の点より後はその値のままにします
+type RatePoint struct {
+	Time      float64 `json:"time"`
+	PerSecond float64 `json:"per_second"`
+}
+
+// SpawnBand は From 秒以降に出現する敵の種類と重みです
+type SpawnBand struct {
+	From    float64       `json:"from"`
+	Enemies []SpawnWeight `json:"enemies"`
+}
+
+// SpawnWeight は敵の種類ごとの出現の重みです
+type SpawnWeight struct {
+	Type   EnemyType `json:"id"`
+	Weight int       `json:"weight"`
+}
+
+// WaveEvent は Time 秒に敵の隊列を出現させるイベントです
+type WaveEvent struct {
+	Time      float64   `json:"time"`
+	Repeat    float64   `json:"repeat"` // 0 より大きければこの間隔（秒）で繰り返す
+	Formation Formation `json:"formation"`
+	Enemy     EnemyType `json:"enemy"`
+	Count     int       `json:"count"`
+	Radius    float64   `json:"radius"`  // 輪の半径
+	Spread    float64   `json:"spread"`  // 群れの広がり
+	Warning   float64   `json:"warning"` // 出現の何秒前から警告を出すか
+	Message   string    `json:"message"` // 警告の文言。メッセージカタログの ID なら表示する言語の文言になる
+}
+
+// rateAt は gameTime 秒の時点での1秒あたりの出現数を返します
+func (s *StageParams) rateAt(gameTime float64) float64 {
+	if gameTime <= s.Rate[0].Time {
+		return s.Rate[0].PerSecond
+	}
+	for i := 1; i < len(s.Rate); i++ {
+		a, b := s.Rate[i-1], s.Rate[i]
+		if gameTime < b.Time {
+			t := (gameTime - a.Time) / (b.Time - a.Time)
+			return a.PerSecond + (b.PerSecond-a.PerSecond)*t
+		}
+	}
+	return s.Rate[len(s.Rate)-1].PerSecond
+}
+
+// director はステージのタイムラインに従って敵を出現させます
+type director struct {
+	stage  StageParams
+	budget float64   // 出現待ちの敵の数（端数を持ち越す）
+	next   []float64 // イベントごとの次の発生時刻
+}
+
+// newDirector はゲームの開始時から stage を進める director を作成します
+func newDirector(stage StageParams) director {
+	d := director{stage: stage, next: make([]float64, len(stage.Events))}
+	for i, ev := range stage.Events {
+		d.next[i] = ev.Time
+	}
+	return d
+}
+
+// Stage は遊んでいるステージを返します
+func (g *Game) Stage() StageParams {
+	return g.wave.stage
+}
+
+// updateWaves は1ティック分タイムラインを進めて敵を出現させます
+func (g *Game) updateWaves(now float64) {
+	d := &g.wave
+
+	d.budget += d.stage.rateAt(now) / TicksPerSecond
+	for d.budget >= 1 {
+		d.budget--
+		// 上限に達しているときは出現させずに捨てる
+		if len(g.Enemies) < d.stage.MaxEnemies {
+			g.spawnEnemy()
+		}
+	}
+
+	for i, ev := range d.stage.Events {
+		for now >= d.next[i] {
+			g.spawnWave(ev)
+			if ev.Repeat > 0 {
+				d.next[i] += ev.Repeat
+			} else {
+				d.next[i] = math.Inf(1)
+			}
+		}
+	}
+}
+
+// spawnWave はイベント ev の隊列を出現させます
+func (g *Game) spawnWave(ev WaveEvent) {
+	params, _ := g.defs.Enemy(ev.Enemy)
+	count := ev.Count
+	if ev.Formation != FormationBoss {
+		count = min(count, g.wave.stage.MaxEnemies-len(g.Enemies))
+	}
+
+	switch ev.Formation {
+	case FormationRing:
+		// 輪は Count 体が均等に並び、全員がプレイヤーに向かうので閉じていく
+		for i := 0; i < count; i++ {
+			angle := 2 * math.Pi * float64(i) / float64(ev.Count)
+			x := g.Player.X + math.Cos(angle)*ev.Radius
+			y := g.Player.Y + math.Sin(angle)*ev.Radius
+			g.Enemies = append(g.Enemies, newEnemy(params, x, y))
+		}
+	case FormationSwarm:
+		side := g.rng.Intn(4)
+		center := g.rng.Float64()
+		for i := 0; i < count; i++ {
+			x, y := screenEdge(side, center)
+			// 群れは辺に沿った方向と外側に向かってばらける
+			x += (g.rng.Float64()*2 - 1) * ev.Spread
+			y += (g.rng.Float64()*2 - 1) * ev.Spread
+			switch side {
+			case 0:
+				y = min(y, -30)
+			case 1:
+				x = max(x, ScreenWidth+30)
+			case 2:
+				y = max(y, ScreenHeight+30)
+			case 3:
+				x = min(x, -30)
+			}
+			g.Enemies = append(g.Enemies, newEnemy(params, g.camera.X+x, g.camera.Y+y))
+		}
+	case FormationBoss:
+		for i := 0; i < count; i++ {
+			x, y := g.edgePosition(g.rng.Intn(4), g.rng.Float64())
+			g.Enemies = append(g.Enemies, newEnemy(params, x, y))
+		}
+	}
+}
+
+// Warning は間もなく起こるイベントの警告の文言を返します
+// 警告中のイベントがなければ空文字列を返します
+func (g *Game) Warning() string {
+	now := g.clock.Now()
+	for i, ev := range g.wave.stage.Events {
+		if ev.Warning > 0 && ev.Message != "" && now < g.wave.next[i] && now >= g.wave.next[i]-ev.Warning {
+			return ev.Message
+		}
+	}
+	return ""
+}
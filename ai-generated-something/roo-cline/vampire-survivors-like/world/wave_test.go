@@ -0,0 +1,110 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+func newWaveTestGame(stage StageParams) *Game {
+	g := newTestGame()
+	g.wave = newDirector(stage)
+	return g
+}
+
+func TestRateCurve(t *testing.T) {
+	stage := StageParams{Rate: []RatePoint{{0, 1}, {10, 3}, {20, 2}}}
+	tests := []struct {
+		time, want float64
+	}{
+		{0, 1},
+		{5, 2},
+		{10, 3},
+		{15, 2.5},
+		{100, 2},
+	}
+	for _, tt := range tests {
+		if got := stage.rateAt(tt.time); got != tt.want {
+			t.Errorf("rateAt(%v) = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestSpawnRateAndEnemyCap(t *testing.T) {
+	stage := DefaultDefinitions().Stages[0]
+	stage.Rate = []RatePoint{{0, 6}}
+	stage.Events = nil
+	stage.MaxEnemies = 5
+	g := newWaveTestGame(stage)
+
+	// 1秒で6体出現するはずだが、上限で止まる
+	for range TicksPerSecond {
+		g.updateWaves(g.clock.Now())
+		g.clock.Tick()
+	}
+	if len(g.Enemies) != 5 {
+		t.Errorf("len(Enemies) = %d, want 5", len(g.Enemies))
+	}
+}
+
+func TestRingFormation(t *testing.T) {
+	stage := DefaultDefinitions().Stages[0]
+	stage.Rate = []RatePoint{{0, 0}}
+	stage.Events = []WaveEvent{{Time: 1, Formation: FormationRing, Enemy: EnemyNormal, Count: 8, Radius: 300}}
+	g := newWaveTestGame(stage)
+
+	g.updateWaves(0.5)
+	if len(g.Enemies) != 0 {
+		t.Fatalf("len(Enemies) = %d before the event, want 0", len(g.Enemies))
+	}
+	g.updateWaves(1)
+	if len(g.Enemies) != 8 {
+		t.Fatalf("len(Enemies) = %d, want 8", len(g.Enemies))
+	}
+	for _, e := range g.Enemies {
+		dist := math.Hypot(e.X-g.Player.X, e.Y-g.Player.Y)
+		if math.Abs(dist-300) > 1e-6 {
+			t.Errorf("enemy at distance %v, want 300", dist)
+		}
+	}
+
+	// 繰り返さないイベントは一度だけ起こる
+	g.updateWaves(100)
+	if len(g.Enemies) != 8 {
+		t.Errorf("len(Enemies) = %d after the event, want 8", len(g.Enemies))
+	}
+}
+
+func TestBossWarning(t *testing.T) {
+	stage := DefaultDefinitions().Stages[0]
+	stage.Rate = []RatePoint{{0, 0}}
+	stage.MaxEnemies = 1
+	stage.Events = []WaveEvent{{
+		Time: 10, Repeat: 20, Formation: FormationBoss, Enemy: EnemyBoss, Count: 1,
+		Warning: 3, Message: "boss",
+	}}
+	g := newWaveTestGame(stage)
+	g.Enemies = []*Enemy{newEnemy(EnemyParams{Type: EnemyNormal, HP: 1}, 0, 0)}
+
+	for g.clock.Now() < 30.5 {
+		g.clock.Tick()
+		now := g.clock.Now()
+		g.updateWaves(now)
+
+		warning := g.Warning() != ""
+		wantWarning := (now >= 7 && now < 10) || (now >= 27 && now < 30)
+		if warning != wantWarning {
+			t.Fatalf("at %.2fs: Warning() = %q, want warning %v", now, g.Warning(), wantWarning)
+		}
+	}
+
+	// ボスは出現数の上限を無視して、繰り返し出現する
+	bosses := 0
+	for _, e := range g.Enemies {
+		if e.Type == EnemyBoss {
+			bosses++
+		}
+	}
+	if bosses != 2 {
+		t.Errorf("bosses = %d, want 2", bosses)
+	}
+}
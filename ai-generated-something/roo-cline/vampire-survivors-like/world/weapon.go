@@ -0,0 +1,183 @@
+package world
+
+import "math"
+
+// 武器の種類（定義ファイルの武器の id）
+type WeaponType string
+
+// 組み込みの武器
+const (
+	WeaponMelee  WeaponType = "melee"
+	WeaponRanged WeaponType = "ranged"
+	WeaponAura   WeaponType = "aura"
+	WeaponSpiral WeaponType = "spiral"
+)
+
+// 武器の攻撃方法
+type WeaponBehavior string
+
+const (
+	BehaviorMelee  WeaponBehavior = "melee"  // 近接武器（回転攻撃）
+	BehaviorRanged WeaponBehavior = "ranged" // 遠距離武器（直線攻撃）
+	BehaviorAura   WeaponBehavior = "aura"   // オーラ攻撃（常時ダメージ）
+	BehaviorSpiral WeaponBehavior = "spiral" // 螺旋攻撃
+)
+
+func (b WeaponBehavior) valid() bool {
+	switch b {
+	case BehaviorMelee, BehaviorRanged, BehaviorAura, BehaviorSpiral:
+		return true
+	}
+	return false
+}
+
+// shoots は弾を撃つ攻撃方法かどうかを返します
+func (b WeaponBehavior) shoots() bool {
+	return b == BehaviorRanged || b == BehaviorSpiral
+}
+
+// 攻撃の方向（ラジアン）
+type AttackDirection struct {
+	Angle float64
+	Speed float64 // 弾の移動速度（螺旋攻撃用）
+}
+
+// 武器の基本パラメータ
+type WeaponParams struct {
+	WeaponType      WeaponType     `json:"id"`
+	Name            string         `json:"name"`
+	Behavior        WeaponBehavior `json:"behavior"`
+	AttackInterval  float64        `json:"attack_interval"`  // 攻撃間隔
+	AttackRange     float64        `json:"attack_range"`     // 攻撃範囲
+	AttackDamage    int            `json:"attack_damage"`    // 攻撃力
+	ProjectileSpeed float64        `json:"projectile_speed"` // 弾の1ティックあたりの移動距離（遠距離武器用）
+	Pierce          int            `json:"pierce"`           // 弾が貫通できる敵の数（0 なら最初に当たった敵で消える）
+	HitCooldown     float64        `json:"hit_cooldown"`     // 貫通する弾が同じ敵に再び当たるまでの秒数（0 なら同じ敵には1度だけ）
+	Knockback       float64        `json:"knockback"`        // 当たった敵を押し戻す距離
+	ProjectileCount int            `json:"projectile_count"` // 1回の攻撃で撃つ弾の数（0 なら1発）
+	Color           Color          `json:"color"`            // 攻撃範囲の表示色
+	Levels          []WeaponLevel  `json:"levels"`           // レベル2以降の強化内容
+	Evolution       bool           `json:"evolution"`        // 進化でのみ手に入る武器か
+}
+
+// WeaponLevel は武器のレベルが1つ上がったときの強化内容です
+type WeaponLevel struct {
+	Damage          int     `json:"damage"`           // 攻撃力の増加量
+	Interval        float64 `json:"interval"`         // 攻撃間隔の増減（負の値で短くなる）
+	Range           float64 `json:"range"`            // 攻撃範囲の増加量
+	ProjectileCount int     `json:"projectile_count"` // 弾の数の増加量
+	Pierce          int     `json:"pierce"`           // 貫通できる敵の数の増加量
+	Description     string  `json:"description"`
+}
+
+// MaxLevel は武器の最大レベルを返します
+func (p WeaponParams) MaxLevel() int {
+	return 1 + len(p.Levels)
+}
+
+// apply は強化内容を武器のパラメータに反映します
+func (p *WeaponParams) apply(l WeaponLevel) {
+	p.AttackDamage += l.Damage
+	p.AttackInterval += l.Interval
+	p.AttackRange += l.Range
+	p.ProjectileCount += l.ProjectileCount
+	p.Pierce += l.Pierce
+}
+
+// 武器インスタンス
+type Weapon struct {
+	Params         WeaponParams
+	lastAttackTime float64
+	Level          int
+	Direction      AttackDirection
+	Projectiles    []Projectile // 弾のリスト
+}
+
+// 同時に持てる武器の数
+const maxWeapons = 4
+
+// newWeapon は params の武器を作成します
+func newWeapon(params WeaponParams) *Weapon {
+	return &Weapon{
+		Params:         params,
+		lastAttackTime: 0,
+		Level:          1,
+		Direction:      AttackDirection{Angle: 0, Speed: 0},
+		Projectiles:    make([]Projectile, 0),
+	}
+}
+
+// levelUp は武器のレベルを1つ上げます。最大レベルなら何もしません
+func (w *Weapon) levelUp() {
+	if w.Level >= w.Params.MaxLevel() {
+		return
+	}
+	w.Params.apply(w.Params.Levels[w.Level-1])
+	w.Level++
+}
+
+// projectileCount は1回の攻撃で撃つ弾の数を返します
+func (w *Weapon) projectileCount() int {
+	return max(1, w.Params.ProjectileCount)
+}
+
+// attack は武器で攻撃します。実際に攻撃したか（弾を撃ったか、敵に当たったか）を返します
+func (g *Game) attack(weapon *Weapon) bool {
+	weaponType := weapon.Params.WeaponType
+	params := g.Player.EffectiveWeapon(weapon.Params)
+	fired := false
+
+	switch weapon.Params.Behavior {
+	case BehaviorMelee:
+		// 回転攻撃
+		weapon.Direction.Angle += math.Pi / 4 // 45度ずつ回転
+		g.enemiesInRange(g.Player.X, g.Player.Y, params.AttackRange, func(enemy *Enemy) {
+			g.damageEnemy(enemy, params.AttackDamage, weaponType)
+			g.knockbackFromPlayer(enemy, params.Knockback)
+		})
+		fired = true
+
+	case BehaviorRanged:
+		// 最も近い敵に向かって直線攻撃
+		nearestEnemy := g.grid.nearest(g.Player.X, g.Player.Y)
+		if nearestEnemy != nil {
+			dx := nearestEnemy.X - g.Player.X
+			dy := nearestEnemy.Y - g.Player.Y
+			angle := math.Atan2(dy, dx)
+			// 複数の弾は狙った方向を中心に扇状に撃つ
+			n := weapon.projectileCount()
+			const spread = math.Pi / 18
+			for i := 0; i < n; i++ {
+				weapon.fire(params, g.Player.X, g.Player.Y, angle+spread*(float64(i)-float64(n-1)/2))
+			}
+			fired = true
+		}
+
+	case BehaviorAura:
+		// 常時ダメージ
+		g.enemiesInRange(g.Player.X, g.Player.Y, params.AttackRange, func(enemy *Enemy) {
+			g.damageEnemy(enemy, params.AttackDamage, weaponType)
+			g.knockbackFromPlayer(enemy, params.Knockback)
+			fired = true
+		})
+
+	case BehaviorSpiral:
+		// 螺旋攻撃
+		weapon.Direction.Angle += math.Pi / 8
+		// 複数の弾は等間隔の腕にする
+		n := weapon.projectileCount()
+		for i := 0; i < n; i++ {
+			weapon.fire(params, g.Player.X, g.Player.Y, weapon.Direction.Angle+2*math.Pi*float64(i)/float64(n))
+		}
+		fired = true
+	}
+	return fired
+}
+
+// knockbackFromPlayer は敵をプレイヤーから離れる方向に押し戻します
+func (g *Game) knockbackFromPlayer(enemy *Enemy, distance float64) {
+	dx, dy, dist := g.toPlayer(enemy)
+	if dist > 0 {
+		enemy.knockback(-dx, -dy, distance)
+	}
+}
@@ -0,0 +1,228 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+// giveWeapon はプレイヤーに id の武器を持たせます
+func giveWeapon(g *Game, id WeaponType) *Weapon {
+	params, _ := g.defs.Weapon(id)
+	w := newWeapon(params)
+	g.Player.Weapons = append(g.Player.Weapons, w)
+	return w
+}
+
+func TestWeaponLevelUp(t *testing.T) {
+	g := newTestGame()
+	w := giveWeapon(g, WeaponRanged)
+	base := w.Params
+
+	for i := 0; i < base.MaxLevel()+2; i++ {
+		w.levelUp()
+	}
+
+	if w.Level != base.MaxLevel() {
+		t.Errorf("Level = %d, want max level %d", w.Level, base.MaxLevel())
+	}
+	want := base
+	for _, l := range base.Levels {
+		want.apply(l)
+	}
+	if w.Params.AttackDamage != want.AttackDamage || w.Params.ProjectileCount != want.ProjectileCount || w.Params.Pierce != want.Pierce {
+		t.Errorf("Params after max level = %+v, want %+v", w.Params, want)
+	}
+}
+
+func TestWeaponUpgradeTargetsOneWeapon(t *testing.T) {
+	g := newTestGame()
+	melee := g.Player.Weapons[0]
+	aura := giveWeapon(g, WeaponAura)
+
+	var option *SkillOption
+	for _, c := range g.skillCandidates() {
+		if c.Type == SkillWeaponUpgrade && c.Weapon == WeaponAura {
+			option = &c
+		}
+	}
+	if option == nil {
+		t.Fatal("no weapon_upgrade option for aura")
+	}
+
+	g.PendingLevelUps = 1
+	g.applySkill(*option)
+
+	if aura.Level != 2 || melee.Level != 1 {
+		t.Errorf("levels (melee, aura) = (%d, %d), want (1, 2)", melee.Level, aura.Level)
+	}
+}
+
+func TestMaxLevelWeaponIsNotOffered(t *testing.T) {
+	g := newTestGame()
+	melee := g.Player.Weapons[0]
+	for melee.Level < melee.Params.MaxLevel() {
+		melee.levelUp()
+	}
+	for _, c := range g.skillCandidates() {
+		if c.Type == SkillWeaponUpgrade && c.Weapon == melee.Params.WeaponType {
+			t.Fatalf("max level weapon was offered: %+v", c)
+		}
+	}
+}
+
+func TestWeaponEvolution(t *testing.T) {
+	g := newTestGame()
+	melee := g.Player.Weapons[0]
+	for melee.Level < melee.Params.MaxLevel() {
+		melee.levelUp()
+	}
+
+	// 最大レベルになっただけでは進化しない
+	g.PendingLevelUps = 2
+	g.applySkill(SkillOption{Type: SkillPassive, Passive: "wings"})
+	if got := g.Player.Weapons[0].Params.WeaponType; got != WeaponMelee {
+		t.Fatalf("weapon evolved into %q without the required passive", got)
+	}
+
+	g.applySkill(SkillOption{Type: SkillPassive, Passive: "hollow_heart"})
+	if got := g.Player.Weapons[0].Params.WeaponType; got != "bloody_whirl" {
+		t.Errorf("weapon = %q, want bloody_whirl", got)
+	}
+}
+
+func TestWeaponEvolutionKeepsState(t *testing.T) {
+	g := newTestGame()
+	spiral := giveWeapon(g, WeaponSpiral)
+	for spiral.Level < spiral.Params.MaxLevel() {
+		spiral.levelUp()
+	}
+	spiral.lastAttackTime = 12.5
+	spiral.Direction = AttackDirection{Angle: 1.25, Speed: 0.1}
+	spiral.fire(spiral.Params, g.Player.X, g.Player.Y, 0)
+	projectiles := spiral.Projectiles
+
+	var evo Evolution
+	for _, e := range g.defs.Evolutions {
+		if e.Weapon == WeaponSpiral {
+			evo = e
+		}
+	}
+	g.PendingLevelUps = 1
+	g.applySkill(SkillOption{Type: SkillPassive, Passive: evo.Passive})
+
+	evolved := g.Player.weapon(evo.Into)
+	if evolved == nil {
+		t.Fatalf("spiral did not evolve into %q", evo.Into)
+	}
+	// 攻撃の間隔・螺旋の向き・飛んでいる弾は進化の前と変わらない
+	if evolved.lastAttackTime != 12.5 || evolved.Direction != spiral.Direction {
+		t.Errorf("lastAttackTime, Direction = %v, %+v, want 12.5, %+v", evolved.lastAttackTime, evolved.Direction, spiral.Direction)
+	}
+	if len(evolved.Projectiles) != len(projectiles) || evolved.Projectiles[0].X != projectiles[0].X {
+		t.Errorf("Projectiles = %+v, want the %d in flight before evolving", evolved.Projectiles, len(projectiles))
+	}
+}
+
+// newWeaponOptions は新しい武器の選択肢の武器を返します
+func newWeaponOptions(g *Game) []WeaponType {
+	var weapons []WeaponType
+	for _, c := range g.skillCandidates() {
+		if c.Type == SkillNewWeapon {
+			weapons = append(weapons, c.Weapon)
+		}
+	}
+	return weapons
+}
+
+func TestNewWeaponSkipsOwnedWeapons(t *testing.T) {
+	g := newTestGame()
+	giveWeapon(g, WeaponRanged)
+	giveWeapon(g, WeaponAura)
+
+	options := newWeaponOptions(g)
+	if len(options) != 1 || options[0] != WeaponSpiral {
+		t.Fatalf("new weapon options = %v, want only the one not owned yet (spiral)", options)
+	}
+	g.PendingLevelUps = 1
+	g.applySkill(SkillOption{Type: SkillNewWeapon, Weapon: WeaponSpiral})
+	if got := g.Player.Weapons[len(g.Player.Weapons)-1].Params.WeaponType; got != WeaponSpiral {
+		t.Errorf("new weapon = %q, want spiral", got)
+	}
+
+	// 持てる数に達したら新しい武器は出さない
+	if options := newWeaponOptions(g); len(options) != 0 {
+		t.Errorf("new weapon options with %d weapons = %v, want none", len(g.Player.Weapons), options)
+	}
+}
+
+func TestNewWeaponSkipsEvolvedWeapons(t *testing.T) {
+	g := newTestGame()
+	evolved, _ := g.defs.Weapon("bloody_whirl")
+	g.Player.Weapons[0] = newWeapon(evolved)
+
+	for _, w := range newWeaponOptions(g) {
+		if w == WeaponMelee || w == "bloody_whirl" {
+			t.Errorf("offered %q after evolving melee", w)
+		}
+	}
+}
+
+func TestProjectileCount(t *testing.T) {
+	g := newTestGame()
+	w := giveWeapon(g, WeaponSpiral)
+	w.Params.ProjectileCount = 3
+
+	g.attack(w)
+
+	if len(w.Projectiles) != 3 {
+		t.Errorf("fired %d projectiles, want 3", len(w.Projectiles))
+	}
+}
+
+func TestKnockedBackEnemyIsHitBySecondWeapon(t *testing.T) {
+	g := newTestGame()
+	melee := g.Player.Weapons[0]
+	aura := giveWeapon(g, WeaponAura)
+	for _, w := range g.Player.Weapons {
+		w.lastAttackTime = -w.Params.AttackInterval
+	}
+	enemy := placeEnemy(g, EnemyTank, 70, 0)
+	hp := enemy.HP
+
+	g.Step(Input{})
+
+	// 近接武器で押し戻された敵にも、同じティックのオーラが当たる
+	want := hp - g.Player.EffectiveWeapon(melee.Params).AttackDamage - g.Player.EffectiveWeapon(aura.Params).AttackDamage
+	if enemy.HP != want {
+		t.Errorf("HP = %d, want %d; the second weapon missed the knocked-back enemy", enemy.HP, want)
+	}
+	if enemy.X-g.Player.X <= 70 {
+		t.Errorf("enemy at %v, want it knocked back beyond 70", enemy.X-g.Player.X)
+	}
+}
+
+func TestKnockback(t *testing.T) {
+	g := newTestGame()
+	weapon := g.Player.Weapons[0]
+	enemy := placeEnemy(g, EnemyTank, 50, 0)
+	boss := placeEnemy(g, EnemyBoss, 0, 50)
+	boss.params.KnockbackResist = 1
+
+	g.attack(weapon)
+	for range TicksPerSecond {
+		g.updateEnemy(enemy)
+		g.updateEnemy(boss)
+		if enemy.kbX == 0 {
+			break
+		}
+	}
+
+	// 耐性の分だけ押し戻す距離が減る
+	want := 50 + weapon.Params.Knockback*(1-enemy.params.KnockbackResist)
+	if got := enemy.X - g.Player.X; math.Abs(got-want) > 1 {
+		t.Errorf("enemy pushed to %v, want about %v", got, want)
+	}
+	if boss.kbX != 0 || boss.kbY != 0 {
+		t.Errorf("boss knockback = (%v, %v), want none", boss.kbX, boss.kbY)
+	}
+}